@@ -51,9 +51,11 @@ func createOneToOne(b body, typeOf reflect.Type) any {
 		b.driver.KeywordHandler(utils.TableNamePattern(b.typeOf.Name())),
 		b.fieldName,
 		b.mapp.tableId,
-		b.fieldId,
+		b.field.Index,
 		b.driver,
 	)
+	mto.sensitive = isSensitive(b.field)
+	mto.mode = getFieldMode(b.field)
 	return mto
 }
 
@@ -100,9 +102,11 @@ func createManyToOne(b body, typeOf reflect.Type) any {
 		b.driver.KeywordHandler(utils.TableNamePattern(b.typeOf.Name())),
 		b.fieldName,
 		b.mapp.tableId,
-		b.fieldId,
+		b.field.Index,
 		b.driver,
 	)
+	mto.sensitive = isSensitive(b.field)
+	mto.mode = getFieldMode(b.field)
 	return mto
 }
 
@@ -111,10 +115,14 @@ type attributeStrings struct {
 	tableId       int
 	tableName     string
 	attributeName string
-	fieldId       int
+	fieldId       []int // index of the field on the struct
+	sensitive     bool
+	mode          fieldMode
+	codec         *Codec // codec of the column, nil uses the codec of the type
+	json          bool   // field with the json tag
 }
 
-func createAttributeStrings(db *DB, table string, attributeName string, tableId, fieldId int, Driver Driver) attributeStrings {
+func createAttributeStrings(db *DB, table string, attributeName string, tableId int, fieldId []int, Driver Driver) attributeStrings {
 	return attributeStrings{
 		db:            db,
 		tableName:     table,
@@ -149,7 +157,7 @@ func (p *pk) getAttributeName() string {
 	return p.attributeName
 }
 
-func createPk(db *DB, table string, attributeName string, autoIncrement bool, tableId, fieldId int, Driver Driver) *pk {
+func createPk(db *DB, table string, attributeName string, autoIncrement bool, tableId int, fieldId []int, Driver Driver) *pk {
 	table = Driver.KeywordHandler(utils.TableNamePattern(table))
 	return &pk{
 		attributeStrings: createAttributeStrings(db, table, attributeName, tableId, fieldId, Driver),
@@ -180,7 +188,23 @@ func (a *att) getAttributeName() string {
 	return a.attributeName
 }
 
-func createAtt(db *DB, attributeName string, table string, tableId, fieldId int, d Driver) *att {
+func (a *attributeStrings) isSensitive() bool {
+	return a.sensitive
+}
+
+func (a *attributeStrings) getMode() fieldMode {
+	return a.mode
+}
+
+func (a *attributeStrings) getCodec() *Codec {
+	return a.codec
+}
+
+func (a *attributeStrings) isJSONColumn() bool {
+	return a.json
+}
+
+func createAtt(db *DB, attributeName string, table string, tableId int, fieldId []int, d Driver) *att {
 	return &att{
 		attributeStrings: createAttributeStrings(db, table, attributeName, tableId, fieldId, d)}
 }
@@ -254,19 +278,19 @@ func (o *oneToOne) writeAttributeInsert(b *builder) {
 	b.fieldIds = append(b.fieldIds, o.fieldId)
 }
 
-func (p *pk) getFieldId() int {
+func (p *pk) getFieldId() []int {
 	return p.fieldId
 }
 
-func (a *att) getFieldId() int {
+func (a *att) getFieldId() []int {
 	return a.fieldId
 }
 
-func (m *manyToOne) getFieldId() int {
+func (m *manyToOne) getFieldId() []int {
 	return m.fieldId
 }
 
-func (o *oneToOne) getFieldId() int {
+func (o *oneToOne) getFieldId() []int {
 	return o.fieldId
 }
 
@@ -292,6 +316,7 @@ type functionResult struct {
 	attributeName string
 	table         string
 	functionType  enum.FunctionType
+	jsonPath      string
 	db            *DB
 }
 
@@ -299,7 +324,8 @@ func (f *functionResult) buildAttributeSelect(b *builder) {
 	b.query.Attributes = append(b.query.Attributes, model.Attribute{
 		Table:        f.table,
 		Name:         f.attributeName,
-		FunctionType: f.functionType})
+		FunctionType: f.functionType,
+		JSONPath:     f.jsonPath})
 }
 
 func (f *functionResult) getDb() *DB {
@@ -12,16 +12,17 @@ import (
 type builder struct {
 	query        model.Query
 	modelStart   time.Time
-	pkFieldId    int //insert
+	pkFieldId    []int //insert
 	inserts      []field
 	fields       []field
 	fieldsSelect []fieldSelect
-	fieldIds     []int           //insert and update
+	fieldIds     [][]int         //insert and update
 	joins        []enum.JoinType //select
 	joinsArgs    []field         //select
 	tables       []int
 	brs          []model.Operation
 	sets         []set
+	params       map[int]param // argument position => param, select
 }
 
 type set struct {
@@ -36,6 +37,30 @@ func createBuilder(typeQuery enum.QueryType) builder {
 	}
 }
 
+// copy returns a deep copy of the builder without the arguments,
+// the copy can be build without changing the state of b
+func (b *builder) copy() builder {
+	c := *b
+	c.inserts = slices.Clone(b.inserts)
+	c.fields = slices.Clone(b.fields)
+	c.fieldsSelect = slices.Clone(b.fieldsSelect)
+	c.fieldIds = slices.Clone(b.fieldIds)
+	c.joins = slices.Clone(b.joins)
+	c.joinsArgs = slices.Clone(b.joinsArgs)
+	c.tables = slices.Clone(b.tables)
+	c.brs = slices.Clone(b.brs)
+	c.sets = slices.Clone(b.sets)
+
+	c.query.Attributes = slices.Clone(b.query.Attributes)
+	c.query.Tables = slices.Clone(b.query.Tables)
+	c.query.Joins = slices.Clone(b.query.Joins)
+	c.query.WhereOperations = slices.Clone(b.query.WhereOperations)
+	c.query.Arguments = nil
+	c.query.SensitiveArguments = nil
+	c.params = nil
+	return c
+}
+
 func (b *builder) buildSelect() {
 	b.query.Attributes = make([]model.Attribute, 0, len(b.fieldsSelect))
 
@@ -67,14 +92,14 @@ func (b *builder) buildSqlSelect() {
 	b.query.Header.ModelBuild = time.Since(b.modelStart)
 }
 
-func (b *builder) buildSqlInsert(v reflect.Value) (pkFieldId int) {
+func (b *builder) buildSqlInsert(v reflect.Value) (pkFieldId []int) {
 	b.buildInsert()
 	pkFieldId = b.buildValues(v)
 	b.query.Header.ModelBuild = time.Since(b.modelStart)
 	return pkFieldId
 }
 
-func (b *builder) buildSqlInsertBatch(v reflect.Value) (pkFieldId int) {
+func (b *builder) buildSqlInsertBatch(v reflect.Value) (pkFieldId []int) {
 	b.buildInsert()
 	pkFieldId = b.buildBatchValues(v)
 	b.query.Header.ModelBuild = time.Since(b.modelStart)
@@ -98,6 +123,13 @@ func (b *builder) buildWhere() {
 	for _, v := range b.brs {
 		switch v.Type {
 		case enum.OperationWhere:
+			b.sensitiveArgument(v.Sensitive)
+			if p, ok := v.Value.(param); ok {
+				if b.params == nil {
+					b.params = make(map[int]param)
+				}
+				b.params[len(b.query.Arguments)] = p
+			}
 			b.query.Arguments = append(b.query.Arguments, v.Value.GetValue())
 
 			b.query.WhereOperations = append(b.query.WhereOperations, model.Where{
@@ -105,6 +137,7 @@ func (b *builder) buildWhere() {
 					Name:         v.Attribute,
 					Table:        v.Table,
 					FunctionType: v.Function,
+					JSONPath:     v.JSONPath,
 				},
 				Operator: v.Operator,
 				Type:     v.Type,
@@ -122,8 +155,9 @@ func (b *builder) buildWhere() {
 		case enum.OperationIsWhere:
 			b.query.WhereOperations = append(b.query.WhereOperations, model.Where{
 				Attribute: model.Attribute{
-					Name:  v.Attribute,
-					Table: v.Table,
+					Name:     v.Attribute,
+					Table:    v.Table,
+					JSONPath: v.JSONPath,
 				},
 				Operator: v.Operator,
 				Type:     v.Type,
@@ -143,11 +177,13 @@ func (b *builder) buildWhere() {
 			switch valueOf.Kind() {
 			case reflect.Slice:
 				for i := range valueOf.Len() {
+					b.sensitiveArgument(v.Sensitive)
 					b.query.Arguments = append(b.query.Arguments, valueOf.Index(i).Interface())
 					where.SizeIn++
 				}
 			case reflect.Array:
 				for i := range valueOf.Len() {
+					b.sensitiveArgument(v.Sensitive)
 					b.query.Arguments = append(b.query.Arguments, valueOf.Index(i).Interface())
 					where.SizeIn++
 				}
@@ -168,6 +204,13 @@ func (b *builder) buildWhere() {
 	}
 }
 
+// sensitiveArgument marks the next argument as sensitive
+func (b *builder) sensitiveArgument(sensitive bool) {
+	if sensitive {
+		b.query.SensitiveArguments = append(b.query.SensitiveArguments, len(b.query.Arguments))
+	}
+}
+
 func (b *builder) buildTables() {
 	if len(b.joins) != 0 {
 		b.query.Joins = make([]model.Join, 0, len(b.joins))
@@ -201,7 +244,7 @@ func buildJoins(b *builder, join enum.JoinType, f1, f2 field, tables []int, tabl
 
 func (b *builder) buildInsert() {
 
-	b.fieldIds = make([]int, 0, len(b.fields))
+	b.fieldIds = make([][]int, 0, len(b.fields))
 	b.query.Attributes = make([]model.Attribute, 0, len(b.fields))
 
 	f := b.fields[0]
@@ -218,16 +261,19 @@ func (b *builder) buildInsert() {
 
 }
 
-func (b *builder) buildValues(value reflect.Value) int {
+func (b *builder) buildValues(value reflect.Value) []int {
 	//update to index
 	b.query.Arguments = make([]any, 0, len(b.fieldIds))
+	b.query.SensitiveArguments = nil
 
 	c := 2
-	b.query.Arguments = append(b.query.Arguments, value.Field(b.fieldIds[0]).Interface())
+	b.sensitiveArgument(b.inserts[0].isSensitive())
+	b.query.Arguments = append(b.query.Arguments, columnArgument(b.inserts[0], fieldValue(value, b.fieldIds[0]).Interface()))
 
 	a := b.fieldIds[1:]
 	for i := range a {
-		b.query.Arguments = append(b.query.Arguments, value.Field(a[i]).Interface())
+		b.sensitiveArgument(b.inserts[i+1].isSensitive())
+		b.query.Arguments = append(b.query.Arguments, columnArgument(b.inserts[i+1], fieldValue(value, a[i]).Interface()))
 		c++
 	}
 	b.query.SizeArguments = len(b.fieldIds)
@@ -235,8 +281,9 @@ func (b *builder) buildValues(value reflect.Value) int {
 
 }
 
-func (b *builder) buildBatchValues(value reflect.Value) int {
+func (b *builder) buildBatchValues(value reflect.Value) []int {
 	b.query.Arguments = make([]any, 0, len(b.fieldIds))
+	b.query.SensitiveArguments = nil
 
 	c := 1
 	buildBatchValues(value.Index(0), b, &c)
@@ -252,11 +299,13 @@ func (b *builder) buildBatchValues(value reflect.Value) int {
 }
 
 func buildBatchValues(value reflect.Value, b *builder, c *int) {
-	b.query.Arguments = append(b.query.Arguments, value.Field(b.fieldIds[0]).Interface())
+	b.sensitiveArgument(b.inserts[0].isSensitive())
+	b.query.Arguments = append(b.query.Arguments, columnArgument(b.inserts[0], fieldValue(value, b.fieldIds[0]).Interface()))
 
 	a := b.fieldIds[1:]
 	for i := range a {
-		b.query.Arguments = append(b.query.Arguments, value.Field(a[i]).Interface())
+		b.sensitiveArgument(b.inserts[i+1].isSensitive())
+		b.query.Arguments = append(b.query.Arguments, columnArgument(b.inserts[i+1], fieldValue(value, a[i]).Interface()))
 		*c++
 	}
 }
@@ -272,9 +321,11 @@ func (b *builder) buildSets() {
 	b.query.Tables = make([]string, 1)
 	b.query.Tables[0] = b.sets[0].attribute.table()
 	b.query.Arguments = make([]any, 0, len(b.sets))
+	b.query.SensitiveArguments = nil
 
 	for i := range b.sets {
 		b.query.Attributes = append(b.query.Attributes, model.Attribute{Name: b.sets[i].attribute.getAttributeName()})
-		b.query.Arguments = append(b.query.Arguments, b.sets[i].value)
+		b.sensitiveArgument(b.sets[i].attribute.isSensitive())
+		b.query.Arguments = append(b.query.Arguments, columnArgument(b.sets[i].attribute, b.sets[i].value))
 	}
 }
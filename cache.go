@@ -0,0 +1,347 @@
+package goe
+
+import (
+	container "container/list"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-goe/goe/model"
+)
+
+// Cache stores the results of the selects using Cache(ttl), it's set on [DatabaseConfig].
+//
+// The values are invalidated by the tables used on the select,
+// after any insert, update or delete on the same table made by goe,
+// the writes made on a transaction invalidate the tables after commit.
+type Cache interface {
+	Get(key string) (any, bool)
+	Set(key string, value any, tables []string, ttl time.Duration)
+	InvalidateTables(tables ...string)
+}
+
+// MemoryCache is a in-memory [Cache] that removes the least recently used values.
+type MemoryCache struct {
+	mu     sync.Mutex
+	size   int
+	lru    *container.List
+	items  map[string]*container.Element
+	tables map[string]map[string]struct{}
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	tables  []string
+	expires time.Time
+}
+
+// NewMemoryCache returns a [MemoryCache] that holds up to size values.
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{
+		size:   max(size, 1),
+		lru:    container.New(),
+		items:  make(map[string]*container.Element),
+		tables: make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *MemoryCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return entry.value, true
+}
+
+func (c *MemoryCache) Set(key string, value any, tables []string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+
+	c.items[key] = c.lru.PushFront(&cacheEntry{key: key, value: value, tables: tables, expires: time.Now().Add(ttl)})
+	for _, t := range tables {
+		if c.tables[t] == nil {
+			c.tables[t] = make(map[string]struct{})
+		}
+		c.tables[t][key] = struct{}{}
+	}
+
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *MemoryCache) InvalidateTables(tables ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, t := range tables {
+		for key := range c.tables[t] {
+			if e, ok := c.items[key]; ok {
+				c.remove(e)
+			}
+		}
+	}
+}
+
+// Len returns the number of values on cache.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *MemoryCache) remove(e *container.Element) {
+	entry := c.lru.Remove(e).(*cacheEntry)
+	delete(c.items, entry.key)
+	for _, t := range entry.tables {
+		delete(c.tables[t], entry.key)
+		if len(c.tables[t]) == 0 {
+			delete(c.tables, t)
+		}
+	}
+}
+
+// cacheKey returns the key of a select, using the result type, sql and a hash of the arguments,
+// so the values of sensitive attributes are not stored on the keys;
+// the selects are not cached if the driver is not a [Renderer]
+func cacheKey[T any](driver Driver, query *model.Query) (string, bool) {
+	// render encodes the arguments of a copy, the builder keeps the arguments to run
+	q := *query
+	sql, args, err := render(driver, &q)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	for _, arg := range args {
+		writeArgument(h, reflect.ValueOf(arg))
+	}
+	return fmt.Sprintf("%v|%v|%x", reflect.TypeFor[T](), sql, h.Sum(nil)), true
+}
+
+// writeArgument writes the value of a argument, the pointers are dereferenced,
+// so a argument that points to a changed value don't use the old key
+func writeArgument(w io.Writer, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		io.WriteString(w, "nil;")
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			fmt.Fprintf(w, "%v(nil);", v.Type())
+			return
+		}
+		writeArgument(w, v.Elem())
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(w, "%v[", v.Type())
+		for i := range v.Len() {
+			writeArgument(w, v.Index(i))
+		}
+		io.WriteString(w, "];")
+	default:
+		fmt.Fprintf(w, "%#v;", v)
+	}
+}
+
+// cacheTables returns the tables used on a select
+func cacheTables(query *model.Query) []string {
+	tables := make([]string, 0, len(query.Tables)+len(query.Joins))
+	tables = append(tables, query.Tables...)
+	for _, j := range query.Joins {
+		tables = append(tables, j.Table)
+	}
+	return tables
+}
+
+// cachedResult returns the rows from cache, or runs result when iterated and stores
+// all the rows on cache if there is no error and no table was invalidated
+// while the select was running. The rows are copied, so a changed row
+// don't change the cached value
+func cachedResult[T any](config *DatabaseConfig, key string, tables []string, ttl time.Duration, result func() iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if value, ok := config.Cache.Get(key); ok {
+			if rows, ok := value.([]T); ok {
+				for _, row := range rows {
+					if !yield(cloneRow(row), nil) {
+						return
+					}
+				}
+				return
+			}
+		}
+
+		generation := config.generations.load(tables)
+		rows := make([]T, 0)
+		for row, err := range result() {
+			if err != nil {
+				yield(row, err)
+				return
+			}
+			rows = append(rows, cloneRow(row))
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if config.generations.load(tables) == generation {
+			config.Cache.Set(key, rows, tables, ttl)
+		}
+	}
+}
+
+// cloneRow returns a copy of row with new pointers, maps and slices
+func cloneRow[T any](row T) T {
+	cloneValue(reflect.ValueOf(&row).Elem())
+	return row
+}
+
+// cloneValue replaces the pointers, maps and slices reachable from v by copies
+func cloneValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		cloneValue(c.Elem())
+		v.Set(c)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(it.Value())
+			cloneValue(value)
+			c.SetMapIndex(it.Key(), value)
+		}
+		v.Set(c)
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		for i := range c.Len() {
+			cloneValue(c.Index(i))
+		}
+		v.Set(c)
+	case reflect.Array:
+		for i := range v.Len() {
+			cloneValue(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Field(i).CanSet() {
+				cloneValue(v.Field(i))
+			}
+		}
+	}
+}
+
+// invalidateCache removes the cached selects that use the table of a write query,
+// inside a transaction the tables are invalidated on commit
+func (c *DatabaseConfig) invalidateCache(conn Connection, query model.Query) {
+	if c.Cache == nil || len(query.Tables) == 0 {
+		return
+	}
+	if tx, ok := conn.(*transaction); ok {
+		tx.invalidate(query.Tables...)
+		return
+	}
+	c.invalidateTables(query.Tables...)
+}
+
+func (c *DatabaseConfig) invalidateTables(tables ...string) {
+	c.generations.add(tables)
+	c.Cache.InvalidateTables(tables...)
+}
+
+// tableGenerations counts the invalidations of each table, a select
+// is only stored on cache if the tables don't change while it runs
+type tableGenerations struct {
+	mu     sync.Mutex
+	tables map[string]uint64
+}
+
+func (g *tableGenerations) add(tables []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.tables == nil {
+		g.tables = make(map[string]uint64)
+	}
+	for _, t := range tables {
+		g.tables[t]++
+	}
+}
+
+// load returns the sum of the generations of the tables,
+// any invalidation of the tables changes the sum
+func (g *tableGenerations) load(tables []string) uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var sum uint64
+	for _, t := range tables {
+		sum += g.tables[t]
+	}
+	return sum
+}
+
+// transaction queues the cache invalidations of the writes made on the transaction,
+// the tables are invalidated after commit; on rollback the queue is discarded
+type transaction struct {
+	Transaction
+	config *DatabaseConfig
+	mu     sync.Mutex
+	tables []string
+}
+
+func (t *transaction) invalidate(tables ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, table := range tables {
+		if !slices.Contains(t.tables, table) {
+			t.tables = append(t.tables, table)
+		}
+	}
+}
+
+func (t *transaction) Commit() error {
+	err := t.Transaction.Commit()
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	tables := t.tables
+	t.tables = nil
+	t.mu.Unlock()
+	if len(tables) != 0 {
+		t.config.invalidateTables(tables...)
+	}
+	return nil
+}
+
+func (t *transaction) Rollback() error {
+	t.mu.Lock()
+	t.tables = nil
+	t.mu.Unlock()
+	return t.Transaction.Rollback()
+}
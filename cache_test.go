@@ -0,0 +1,218 @@
+package goe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe/enum"
+	"github.com/go-goe/goe/model"
+	"github.com/go-goe/goe/query/update"
+	"github.com/go-goe/goe/query/where"
+)
+
+type CacheAnimal struct {
+	Id   int
+	Name string
+}
+
+type CachePet struct {
+	Id       int
+	Nickname *string
+}
+
+type CacheDatabase struct {
+	CacheAnimal *CacheAnimal
+	CachePet    *CachePet
+	*DB
+}
+
+func TestCache(t *testing.T) {
+	cache := NewMemoryCache(10)
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Cache: cache}
+	driver.result = func(query *model.Query) [][]any {
+		if query.Type == enum.InsertQuery {
+			return [][]any{{1}}
+		}
+		return [][]any{{1, "Cat"}}
+	}
+	db, err := Open[CacheDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	// selectAnimals runs a cached select and returns the queries sent to the driver
+	selectAnimals := func(t *testing.T) int {
+		t.Helper()
+		count := driver.queryCount()
+		animals, err := Select(db.CacheAnimal).From(db.CacheAnimal).Cache(time.Minute).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		if len(animals) != 1 || animals[0].Name != "Cat" {
+			t.Errorf("Expected one animal, got %v", animals)
+		}
+		return driver.queryCount() - count
+	}
+
+	t.Run("Hit", func(t *testing.T) {
+		selectAnimals(t)
+		if queries := selectAnimals(t); queries != 0 {
+			t.Errorf("Expected the select from cache, got %v queries", queries)
+		}
+		if cache.Len() != 1 {
+			t.Errorf("Expected one cached select, got %v", cache.Len())
+		}
+	})
+
+	writes := []struct {
+		desc  string
+		write func(tx Transaction) error
+	}{
+		{desc: "Insert", write: func(tx Transaction) error {
+			return Insert(db.CacheAnimal).OnTransaction(tx).One(&CacheAnimal{Name: "Dog"})
+		}},
+		{desc: "Update", write: func(tx Transaction) error {
+			return Update(db.CacheAnimal).OnTransaction(tx).Sets(update.Set(&db.CacheAnimal.Name, "Dog")).Wheres(where.Equals(&db.CacheAnimal.Id, 1))
+		}},
+		{desc: "Delete", write: func(tx Transaction) error {
+			return Delete(db.CacheAnimal).OnTransaction(tx).Wheres(where.Equals(&db.CacheAnimal.Id, 1))
+		}},
+	}
+
+	for _, w := range writes {
+		t.Run(w.desc, func(t *testing.T) {
+			selectAnimals(t)
+			if err := w.write(nil); err != nil {
+				t.Fatalf("Expected write, got error: %v", err)
+			}
+			if queries := selectAnimals(t); queries != 1 {
+				t.Errorf("Expected the select invalidated by the write, got %v queries", queries)
+			}
+		})
+
+		t.Run(w.desc+"Commit", func(t *testing.T) {
+			selectAnimals(t)
+			tx, err := db.NewTransaction()
+			if err != nil {
+				t.Fatalf("Expected transaction, got error: %v", err)
+			}
+			if err := w.write(tx); err != nil {
+				t.Fatalf("Expected write, got error: %v", err)
+			}
+			if queries := selectAnimals(t); queries != 0 {
+				t.Errorf("Expected the select cached until commit, got %v queries", queries)
+			}
+			if err := tx.Commit(); err != nil {
+				t.Fatalf("Expected commit, got error: %v", err)
+			}
+			if queries := selectAnimals(t); queries != 1 {
+				t.Errorf("Expected the select invalidated by the commit, got %v queries", queries)
+			}
+		})
+
+		t.Run(w.desc+"Rollback", func(t *testing.T) {
+			selectAnimals(t)
+			tx, err := db.NewTransaction()
+			if err != nil {
+				t.Fatalf("Expected transaction, got error: %v", err)
+			}
+			if err := w.write(tx); err != nil {
+				t.Fatalf("Expected write, got error: %v", err)
+			}
+			if err := tx.Rollback(); err != nil {
+				t.Fatalf("Expected rollback, got error: %v", err)
+			}
+			if queries := selectAnimals(t); queries != 0 {
+				t.Errorf("Expected the select cached after rollback, got %v queries", queries)
+			}
+		})
+	}
+
+	t.Run("Stale", func(t *testing.T) {
+		if err := Delete(db.CacheAnimal).Wheres(where.Equals(&db.CacheAnimal.Id, 1)); err != nil {
+			t.Fatalf("Expected delete, got error: %v", err)
+		}
+		result := driver.result
+		defer func() { driver.result = result }()
+		// a write made while the select is running
+		driver.result = func(query *model.Query) [][]any {
+			driver.result = result
+			if err := Delete(db.CacheAnimal).Wheres(where.Equals(&db.CacheAnimal.Id, 1)); err != nil {
+				t.Errorf("Expected delete, got error: %v", err)
+			}
+			return result(query)
+		}
+
+		selectAnimals(t)
+		if cache.Len() != 0 {
+			t.Errorf("Expected the stale result not cached, got %v cached", cache.Len())
+		}
+		if queries := selectAnimals(t); queries != 1 {
+			t.Errorf("Expected the select on the database, got %v queries", queries)
+		}
+	})
+
+	t.Run("PointerArgument", func(t *testing.T) {
+		name := "Cat"
+		selectPets := func(t *testing.T) int {
+			t.Helper()
+			count := driver.queryCount()
+			_, err := Select(db.CachePet).From(db.CachePet).Wheres(where.Equals(&db.CachePet.Nickname, &name)).Cache(time.Minute).AsSlice()
+			if err != nil {
+				t.Fatalf("Expected select, got error: %v", err)
+			}
+			return driver.queryCount() - count
+		}
+
+		selectPets(t)
+		name = "Dog"
+		if queries := selectPets(t); queries != 1 {
+			t.Errorf("Expected the select of the changed argument on the database, got %v queries", queries)
+		}
+		if queries := selectPets(t); queries != 0 {
+			t.Errorf("Expected the select from cache, got %v queries", queries)
+		}
+	})
+
+	t.Run("Lazy", func(t *testing.T) {
+		count := driver.queryCount()
+		rows := Select(db.CacheAnimal).From(db.CacheAnimal).Wheres(where.Equals(&db.CacheAnimal.Id, 2)).Cache(time.Minute).Rows()
+		if queries := driver.queryCount() - count; queries != 0 {
+			t.Errorf("Expected no query before the rows are read, got %v queries", queries)
+		}
+		for _, err := range rows {
+			if err != nil {
+				t.Fatalf("Expected row, got error: %v", err)
+			}
+		}
+		if queries := driver.queryCount() - count; queries != 1 {
+			t.Errorf("Expected the select when the rows are read, got %v queries", queries)
+		}
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		result := driver.result
+		defer func() { driver.result = result }()
+		driver.result = func(query *model.Query) [][]any {
+			return [][]any{{1, "Cat"}}
+		}
+		selectPet := func(t *testing.T) CachePet {
+			t.Helper()
+			pets, err := Select(db.CachePet).From(db.CachePet).Cache(time.Minute).AsSlice()
+			if err != nil || len(pets) != 1 {
+				t.Fatalf("Expected one pet, got %v and error %v", pets, err)
+			}
+			return pets[0]
+		}
+
+		for range 2 {
+			pet := selectPet(t)
+			*pet.Nickname = "Dog"
+		}
+		if pet := selectPet(t); *pet.Nickname != "Cat" {
+			t.Errorf("Expected the cached pet unchanged, got %v", *pet.Nickname)
+		}
+	})
+}
@@ -0,0 +1,9 @@
+module github.com/go-goe/goe/cmd/goe
+
+go 1.24.0
+
+require (
+	github.com/go-goe/goe v0.1.0
+	github.com/go-goe/postgres v0.1.0
+	github.com/go-goe/sqlite v0.1.0
+)
@@ -0,0 +1,124 @@
+// Command goe is the command line tool of goe.
+//
+// Usage:
+//
+//	goe gen -driver sqlite -dsn legacy.db -pkg models -o models.go
+//	goe gen -driver postgres -dsn "user=postgres password=postgres host=localhost port=5432 database=postgres"
+//	goe scan -o goe_scan.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/gen"
+	"github.com/go-goe/postgres"
+	"github.com/go-goe/sqlite"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "gen":
+		err = runGen(os.Args[2:])
+	case "scan":
+		err = runScan(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: goe <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "\tgen\tgenerates the goe structs from a existing database")
+	fmt.Fprintln(os.Stderr, "\tscan\tgenerates the scanners of the structs mapped on the package")
+}
+
+func runGen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	driverName := flags.String("driver", "", "database driver, sqlite or postgres")
+	dsn := flags.String("dsn", "", "sqlite file or postgres connection string")
+	pkg := flags.String("pkg", "models", "package name of the generated file")
+	output := flags.String("o", "", "output file, default is stdout")
+	flags.Parse(args)
+
+	driver, err := openDriver(*driverName, *dsn)
+	if err != nil {
+		return err
+	}
+	if err = driver.Init(); err != nil {
+		return err
+	}
+	defer driver.Close()
+
+	introspector, ok := driver.(goe.Introspector)
+	if !ok {
+		return fmt.Errorf("goe: driver %v don't support introspection, update the driver: %w", driver.Name(), errors.ErrUnsupported)
+	}
+	schema, err := introspector.IntrospectContext(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return gen.Generate(w, schema, gen.Config{Package: *pkg, DataType: introspector.DataType})
+}
+
+func runScan(args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ExitOnError)
+	output := flags.String("o", "goe_scan.go", "output file")
+	flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	var buf bytes.Buffer
+	if err := gen.Scanners(&buf, dir, filepath.Base(*output)); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, *output), buf.Bytes(), 0644)
+}
+
+func openDriver(name, dsn string) (goe.Driver, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("goe: missing -dsn")
+	}
+	switch name {
+	case "sqlite":
+		return sqlite.Open(dsn, sqlite.Config{}), nil
+	case "postgres":
+		return postgres.Open(dsn, postgres.Config{}), nil
+	}
+	return nil, fmt.Errorf("goe: invalid driver %q, use sqlite or postgres", name)
+}
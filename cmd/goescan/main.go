@@ -0,0 +1,38 @@
+// Command goescan generates the scanners of the structs mapped on a package.
+// It's part of the goe module and don't import the drivers, so it runs from
+// any module that requires goe.
+//
+// Usage:
+//
+//	//go:generate go run github.com/go-goe/goe/cmd/goescan -o goe_scan.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-goe/goe/gen"
+)
+
+func main() {
+	output := flag.String("o", "goe_scan.go", "output file")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var buf bytes.Buffer
+	if err := gen.Scanners(&buf, dir, filepath.Base(*output)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(dir, *output), buf.Bytes(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,251 @@
+package goe
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/go-goe/goe/enum"
+	"github.com/go-goe/goe/model"
+)
+
+// Codec converts the values of a Go type to database values and back,
+// it's registered on [DatabaseConfig.Codecs]. Use [NewCodec] to create one.
+type Codec struct {
+	typeOf  reflect.Type
+	columns map[string]string
+	encode  func(any) (any, error)
+	decode  func(any) (any, error)
+}
+
+// NewCodec returns a [Codec] of T, used on the insert and update values,
+// the where arguments, the scan of selects and the migrate of T fields.
+//
+// The columns are the column type used on migrate by driver name, like "PostgreSQL"
+// or "SQLite"; the key "" is used for any other driver. encode converts a T to a value
+// supported by the driver and decode converts a value scanned from the database to a T.
+// A nil value is never encoded or decoded, for pointers to T goe uses NULL.
+//
+// # Example
+//
+//	moneyCodec := goe.NewCodec(map[string]string{"PostgreSQL": "numeric(20,2)", "": "text"},
+//		func(m Money) (any, error) { return m.String(), nil },
+//		func(src any) (Money, error) { return ParseMoney(fmt.Sprint(src)) },
+//	)
+//
+//	db, err := goe.Open[Database](postgres.Open(dsn, postgres.Config{
+//		DatabaseConfig: goe.DatabaseConfig{Codecs: []goe.Codec{moneyCodec}},
+//	}))
+func NewCodec[T any](columns map[string]string, encode func(T) (any, error), decode func(any) (T, error)) Codec {
+	return Codec{
+		typeOf:  reflect.TypeFor[T](),
+		columns: columns,
+		encode: func(v any) (any, error) {
+			return encode(v.(T))
+		},
+		decode: func(src any) (any, error) {
+			return decode(src)
+		},
+	}
+}
+
+// column returns the column type of the codec for the driver
+func (c *Codec) column(driverName string) (string, bool) {
+	if column, ok := c.columns[driverName]; ok {
+		return column, true
+	}
+	column, ok := c.columns[""]
+	return column, ok
+}
+
+// initCodecs indexes the codecs by type, the last codec of a type is used
+func (c *DatabaseConfig) initCodecs() {
+	c.codecs = nil
+	if len(c.Codecs) == 0 {
+		return
+	}
+	c.codecs = make(map[reflect.Type]*Codec, len(c.Codecs))
+	for i := range c.Codecs {
+		c.codecs[c.Codecs[i].typeOf] = &c.Codecs[i]
+	}
+}
+
+// getCodec returns the codec of typeOf, or of the element if typeOf is a pointer
+func (c *DatabaseConfig) getCodec(typeOf reflect.Type) *Codec {
+	if c.codecs == nil {
+		return nil
+	}
+	if codec, ok := c.codecs[typeOf]; ok {
+		return codec
+	}
+	if typeOf.Kind() == reflect.Pointer {
+		return c.codecs[typeOf.Elem()]
+	}
+	return nil
+}
+
+// encodeArguments encodes the arguments that have a codec, the arguments
+// are copied before the first change, so the query builder is not changed
+func (c *DatabaseConfig) encodeArguments(query *model.Query) error {
+	for _, arg := range query.Arguments {
+		if p, ok := arg.(param); ok {
+			return fmt.Errorf("goe: param %q is only valid on a query passed to Compile", p.name)
+		}
+	}
+	return c.encodeValues(query)
+}
+
+// encodeValues encodes the arguments like encodeArguments, the params are kept,
+// used by render so ToSQL shows the params of a query to [Compile]
+func (c *DatabaseConfig) encodeValues(query *model.Query) error {
+	copied := false
+	for i, arg := range query.Arguments {
+		var codec *Codec
+		if a, ok := arg.(codecArgument); ok {
+			codec, arg = a.codec, a.value
+		} else if arg != nil && c.codecs != nil {
+			codec = c.getCodec(reflect.TypeOf(arg))
+		}
+		if codec == nil {
+			continue
+		}
+		encoded, err := codec.encodeValue(arg)
+		if err != nil {
+			return err
+		}
+		if !copied {
+			query.Arguments = slices.Clone(query.Arguments)
+			copied = true
+		}
+		query.Arguments[i] = encoded
+	}
+	return nil
+}
+
+// encodeValue encodes v, a nil or a nil pointer is encoded as NULL
+func (c *Codec) encodeValue(v any) (any, error) {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return nil, nil
+	}
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	encoded, err := c.encode(value.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("goe: error encoding %v: %w", c.typeOf, err)
+	}
+	return encoded, nil
+}
+
+// codecArgument is the argument of a column with its own codec, like the json fields,
+// the value is encoded by the codec of the column instead of the codec of the type
+type codecArgument struct {
+	codec *Codec
+	value any
+}
+
+func (a codecArgument) GetValue() any {
+	return a
+}
+
+// columnValue returns the value of the where operation br on the column of f,
+// the values compared with the whole column are encoded by the codec of the column
+func columnValue(f field, br model.Operation) model.ValueOperation {
+	codec := f.getCodec()
+	if codec == nil || br.Value == nil || br.Function != 0 || br.JSONPath != "" || br.Operator == enum.JSONContains {
+		return br.Value
+	}
+	if p, ok := br.Value.(param); ok {
+		p.codec = codec
+		return p
+	}
+	return codecArgument{codec: codec, value: br.Value.GetValue()}
+}
+
+// columnArgument returns the argument of value for the column of f
+func columnArgument(f field, value any) any {
+	if codec := f.getCodec(); codec != nil {
+		return codecArgument{codec: codec, value: value}
+	}
+	return value
+}
+
+// scanDest returns the scan destination of a new value of typeOf,
+// using a codec scanner if the column of f or typeOf has a codec
+func (c *DatabaseConfig) scanDest(f fieldSelect, typeOf reflect.Type) any {
+	if codec := c.columnCodec(f, typeOf); codec != nil {
+		return &codecScanner{codec: codec, value: reflect.New(typeOf)}
+	}
+	return reflect.New(typeOf).Interface()
+}
+
+// codecDest replaces the pointers of dest that have a codec with a codec scanner,
+// used on the generated scanners; fields are the selected fields of dest
+func (c *DatabaseConfig) codecDest(dest []any, fields []fieldSelect) []any {
+	for i := range dest {
+		var f fieldSelect
+		if i < len(fields) {
+			f = fields[i]
+		}
+		if codec := c.columnCodec(f, reflect.TypeOf(dest[i]).Elem()); codec != nil {
+			dest[i] = &codecScanner{codec: codec, value: reflect.ValueOf(dest[i])}
+		}
+	}
+	return dest
+}
+
+// columnCodec returns the codec of the column of f, or the codec of typeOf
+func (c *DatabaseConfig) columnCodec(f fieldSelect, typeOf reflect.Type) *Codec {
+	if a, ok := f.(field); ok && a.getCodec() != nil {
+		return a.getCodec()
+	}
+	return c.getCodec(typeOf)
+}
+
+// destValue returns the pointer to the scanned value of dest
+func destValue(dest any) reflect.Value {
+	if s, ok := dest.(*codecScanner); ok {
+		return s.value
+	}
+	return reflect.ValueOf(dest)
+}
+
+// codecScanner decodes a column with the codec, value is a pointer
+// to the codec type or to a pointer of the codec type
+type codecScanner struct {
+	codec *Codec
+	value reflect.Value
+}
+
+func (s *codecScanner) Scan(src any) error {
+	target := s.value.Elem()
+	if src == nil {
+		target.SetZero()
+		return nil
+	}
+	decoded, err := s.codec.decode(src)
+	if err != nil {
+		return fmt.Errorf("goe: error decoding %v: %w", s.codec.typeOf, err)
+	}
+	if target.Kind() == reflect.Pointer && target.Type().Elem() == s.codec.typeOf {
+		ptr := reflect.New(s.codec.typeOf)
+		ptr.Elem().Set(reflect.ValueOf(decoded))
+		target.Set(ptr)
+		return nil
+	}
+	target.Set(reflect.ValueOf(decoded))
+	return nil
+}
+
+// errRow is a row that returns err on scan
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...any) error {
+	return r.err
+}
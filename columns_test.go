@@ -0,0 +1,57 @@
+package goe
+
+import (
+	"testing"
+
+	"github.com/go-goe/goe/model"
+	"github.com/go-goe/goe/query/update"
+	"github.com/go-goe/goe/query/where"
+)
+
+type ModeAnimal struct {
+	Id      int
+	Name    string
+	Version int    `goe:"readonly"`
+	Code    string `goe:"insertonly"`
+	Secret  string `goe:"writeonly"`
+}
+
+type ModeDatabase struct {
+	ModeAnimal *ModeAnimal
+	*DB
+}
+
+func TestUpdateColumnModes(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	db, err := Open[ModeDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	testCases := []struct {
+		desc    string
+		set     model.Set
+		wantErr bool
+	}{
+		{desc: "ReadWrite", set: update.Set(&db.ModeAnimal.Name, "Cat")},
+		{desc: "WriteOnly", set: update.Set(&db.ModeAnimal.Secret, "hash")},
+		{desc: "ReadOnly", set: update.Set(&db.ModeAnimal.Version, 2), wantErr: true},
+		{desc: "InsertOnly", set: update.Set(&db.ModeAnimal.Code, "A1"), wantErr: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			count := driver.queryCount()
+			err := Update(db.ModeAnimal).Sets(tC.set).Wheres(where.Equals(&db.ModeAnimal.Id, 1))
+			if tC.wantErr {
+				if err == nil || driver.queryCount() != count {
+					t.Errorf("Expected error without query, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected update, got error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package goe
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-goe/goe/model"
+)
+
+type queryTagsKey struct{}
+
+type queryTag struct {
+	key   string
+	value string
+}
+
+// WithQueryTag returns a copy of ctx with the tag key=value, the tags are added
+// as a sql comment on the queries runned with the context if [DatabaseConfig.QueryTags] is enabled.
+//
+// # Example
+//
+//	ctx = goe.WithQueryTag(ctx, "route", "/animals")
+//	// SELECT ... /*route='%2Fanimals'*/
+//	goe.SelectContext(ctx, db.Animal).From(db.Animal).AsSlice()
+func WithQueryTag(ctx context.Context, key, value string) context.Context {
+	tags, _ := ctx.Value(queryTagsKey{}).([]queryTag)
+	tags = slices.DeleteFunc(slices.Clone(tags), func(t queryTag) bool {
+		return t.key == key
+	})
+	return context.WithValue(ctx, queryTagsKey{}, append(tags, queryTag{key: key, value: value}))
+}
+
+// commentQuery sets the tags of ctx as the query comment, if enabled on config
+func (c *DatabaseConfig) commentQuery(ctx context.Context, query *model.Query) {
+	if c.QueryTags {
+		query.Comment = queryComment(ctx)
+	}
+}
+
+// queryComment returns the tags of ctx on sqlcommenter format,
+// keys are sorted and keys and values are url encoded; quotes and
+// comment delimiters are always encoded
+func queryComment(ctx context.Context) string {
+	tags, _ := ctx.Value(queryTagsKey{}).([]queryTag)
+	if len(tags) == 0 {
+		return ""
+	}
+
+	tags = slices.Clone(tags)
+	slices.SortFunc(tags, func(a, b queryTag) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	var b strings.Builder
+	b.WriteString("/*")
+	for i, t := range tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(escapeTag(t.key))
+		b.WriteString("='")
+		b.WriteString(escapeTag(t.value))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	return b.String()
+}
+
+func escapeTag(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
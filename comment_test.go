@@ -0,0 +1,113 @@
+package goe
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithQueryTag(t *testing.T) {
+	parent := WithQueryTag(context.Background(), "route", "/animals")
+	child := WithQueryTag(parent, "route", "/foods")
+	child = WithQueryTag(child, "app", "api")
+
+	if got := queryComment(parent); got != "/*route='%2Fanimals'*/" {
+		t.Errorf("Expected the parent context unchanged, got %v", got)
+	}
+	if got := queryComment(child); got != "/*app='api',route='%2Ffoods'*/" {
+		t.Errorf("Expected sorted tags with the route replaced, got %v", got)
+	}
+	if got := queryComment(context.Background()); got != "" {
+		t.Errorf("Expected no comment without tags, got %v", got)
+	}
+}
+
+func TestEscapeTag(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		value string
+		want  string
+	}{
+		{desc: "Plain", value: "api", want: "api"},
+		{desc: "Space", value: "get animals", want: "get%20animals"},
+		{desc: "Quote", value: "o'neil", want: "o%27neil"},
+		{desc: "CloseComment", value: "x*/ DROP TABLE animals; /*", want: "x%2A%2F%20DROP%20TABLE%20animals%3B%20%2F%2A"},
+		{desc: "NewLine", value: "a\nb\r\n-- c", want: "a%0Ab%0D%0A--%20c"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got := escapeTag(tC.value)
+			if got != tC.want {
+				t.Errorf("Expected %q, got %q", tC.want, got)
+			}
+			if strings.ContainsAny(got, "*/'\n\r") {
+				t.Errorf("Expected no comment delimiters, quotes or new lines, got %q", got)
+			}
+		})
+	}
+
+	ctx := WithQueryTag(context.Background(), "key*/", "value\n*/")
+	if got := queryComment(ctx); strings.Count(got, "*/") != 1 || !strings.HasSuffix(got, "*/") || strings.Contains(got, "\n") {
+		t.Errorf("Expected a single comment, got %q", got)
+	}
+}
+
+type CommentAnimal struct {
+	Id   int
+	Name string
+}
+
+type CommentDatabase struct {
+	CommentAnimal *CommentAnimal
+	*DB
+}
+
+// tagDriver is a fake driver that writes the query comment
+type tagDriver struct {
+	*fakeDriver
+}
+
+func (d tagDriver) SupportsQueryTags() bool {
+	return true
+}
+
+func TestQueryTags(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	db, err := Open[CommentDatabase](tagDriver{driver})
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	ctx := WithQueryTag(context.Background(), "route", "/animals")
+	if _, err = SelectContext(ctx, db.CommentAnimal).From(db.CommentAnimal).AsSlice(); err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	if comment := driver.lastQuery().Comment; comment != "" {
+		t.Errorf("Expected no comment with QueryTags disabled, got %v", comment)
+	}
+
+	driver.config.QueryTags = true
+	if _, err = SelectContext(ctx, db.CommentAnimal).From(db.CommentAnimal).AsSlice(); err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	if comment := driver.lastQuery().Comment; comment != "/*route='%2Fanimals'*/" {
+		t.Errorf("Expected the route comment, got %v", comment)
+	}
+}
+
+func TestQueryTagsUnsupported(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	driver.config.QueryTags = true
+	if _, err := Open[CommentDatabase](driver); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected unsupported error, got %v", err)
+	}
+
+	replica := newFakeDriver("replica")
+	primary := newFakeDriver("primary")
+	primary.config.QueryTags = true
+	if _, err := OpenReplicas[CommentDatabase](tagDriver{primary}, Replica{Driver: replica}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected unsupported error of the replica, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package goe
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/go-goe/goe/enum"
+	"github.com/go-goe/goe/model"
+)
+
+// Params are the values of the parameters used to run a [Compiled] query, by name.
+type Params map[string]any
+
+// param is the placeholder of a where value, replaced by the [Params] on each run of a [Compiled] query
+type param struct {
+	name   string
+	typeOf reflect.Type
+	codec  *Codec // codec of the column, set on the json fields
+	err    error
+}
+
+func (p param) GetValue() any {
+	return p
+}
+
+// String returns the param as written on the query, showed on the arguments of ToSQL
+func (p param) String() string {
+	return fmt.Sprintf("goe.Param(%q)", p.name)
+}
+
+// Param returns the where operation on a with the value of the parameter name,
+// used on the wheres of a query passed to [Compile]; on each run the value is
+// set by the [Params] and must be a T.
+//
+// Param only supports the operations comparing a with a value, like where.Equals,
+// where.Greater or where.Like; a query with a Param runs only by [Compile].
+//
+// # Example
+//
+//	findByEmail, err := goe.Compile(goe.Select(db.User).From(db.User).
+//		Wheres(goe.Param[string]("email", where.Equals, &db.User.Email)))
+func Param[T any, A *T | **T](name string, operation func(A, T) model.Operation, a A) model.Operation {
+	var v T
+	// a nil pointer compiles to IS NULL
+	if valueOf := reflect.ValueOf(&v).Elem(); valueOf.Kind() == reflect.Pointer {
+		valueOf.Set(reflect.New(valueOf.Type().Elem()))
+	}
+
+	op := operation(a, v)
+	p := param{name: name, typeOf: reflect.TypeFor[T]()}
+	if op.Type != enum.OperationWhere || op.Operator == enum.JSONContains {
+		p.err = fmt.Errorf("goe: invalid param %q, only operations comparing with a value are supported", name)
+	}
+	op.Value = p
+	return op
+}
+
+// value returns the value of p on params
+func (p param) value(params Params) (any, error) {
+	value, ok := params[p.name]
+	if !ok {
+		return nil, fmt.Errorf("goe: missing value for param %q", p.name)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("goe: invalid nil value for param %q", p.name)
+	}
+	if !reflect.TypeOf(value).AssignableTo(p.typeOf) {
+		return nil, fmt.Errorf("goe: invalid value for param %q, expected %v got %T", p.name, p.typeOf, value)
+	}
+	if valueOf := reflect.ValueOf(value); valueOf.Kind() == reflect.Pointer && valueOf.IsNil() {
+		return nil, fmt.Errorf("goe: invalid nil value for param %q", p.name)
+	}
+	if p.codec != nil {
+		return codecArgument{codec: p.codec, value: value}, nil
+	}
+	return value, nil
+}
+
+// Compiled is a select built once by [Compile], that runs with new parameter values.
+// It's safe to run a Compiled query from many goroutines.
+type Compiled[T any] struct {
+	query           model.Query
+	params          map[int]param // argument position => param
+	db              *DB
+	conn            Connection
+	fields          []fieldSelect
+	anonymousStruct bool
+	onPrimary       bool
+	cacheTTL        time.Duration
+	scanner         *Scanner[T]
+	ctx             context.Context
+}
+
+// Compile builds the select once, the where values of the [Param] operations are replaced
+// by the [Params] on each run; the reflection and the query build are not made again.
+//
+// # Example
+//
+//	findByEmail, err := goe.Compile(goe.Select(db.User).From(db.User).
+//		Wheres(goe.Param[string]("email", where.Equals, &db.User.Email)))
+//
+//	users, err := findByEmail.AsSlice(goe.Params{"email": "john@email.com"})
+func Compile[T any](s *stateSelect[T]) (*Compiled[T], error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	s.builder.buildSqlSelect()
+
+	c := &Compiled[T]{
+		query:           s.builder.query,
+		params:          s.builder.params,
+		db:              s.builder.fieldsSelect[0].getDb(),
+		conn:            s.conn,
+		fields:          s.builder.fieldsSelect,
+		anonymousStruct: s.anonymousStruct,
+		onPrimary:       s.onPrimary,
+		cacheTTL:        s.cacheTTL,
+		ctx:             s.ctx,
+	}
+	if !s.anonymousStruct {
+		c.scanner = getScanner[T](s.builder.fieldsSelect)
+	}
+	return c, nil
+}
+
+// OnTransaction returns a copy of the compiled query that runs inside tx.
+func (c *Compiled[T]) OnTransaction(tx Transaction) *Compiled[T] {
+	compiled := *c
+	compiled.conn = tx
+	return &compiled
+}
+
+// Rows runs the query with the params and return a iterator on rows,
+// using the context of the select passed to [Compile].
+func (c *Compiled[T]) Rows(params Params) iter.Seq2[T, error] {
+	return c.RowsContext(c.ctx, params)
+}
+
+// RowsContext runs the query with the params and ctx and return a iterator on rows.
+func (c *Compiled[T]) RowsContext(ctx context.Context, params Params) iter.Seq2[T, error] {
+	query := c.query
+	query.Arguments = slices.Clone(c.query.Arguments)
+	for i, p := range c.params {
+		value, err := p.value(params)
+		if err != nil {
+			return func(yield func(T, error) bool) {
+				var v T
+				yield(v, err)
+			}
+		}
+		query.Arguments[i] = value
+	}
+
+	driver := c.db.driver
+	read := driver
+	if c.conn == nil && !c.onPrimary {
+		read = c.db.readDriver()
+	}
+	conn := c.conn
+	if conn == nil {
+		conn = read.NewConnection()
+	}
+
+	dbConfig := c.db.readConfig(read)
+	if dbConfig.Cache != nil && c.cacheTTL > 0 && c.conn == nil {
+		if key, ok := cacheKey[T](driver, &query); ok {
+			return cachedResult(dbConfig, key, cacheTables(&query), c.cacheTTL, func() iter.Seq2[T, error] {
+				return handlerResult(ctx, conn, query, c.fields, c.anonymousStruct, c.scanner, dbConfig)
+			})
+		}
+	}
+	return handlerResult(ctx, conn, query, c.fields, c.anonymousStruct, c.scanner, dbConfig)
+}
+
+// AsSlice runs the query with the params and return all the rows as a slice.
+func (c *Compiled[T]) AsSlice(params Params) ([]T, error) {
+	return c.AsSliceContext(c.ctx, params)
+}
+
+// AsSliceContext runs the query with the params and ctx and return all the rows as a slice.
+func (c *Compiled[T]) AsSliceContext(ctx context.Context, params Params) ([]T, error) {
+	rows := make([]T, 0, c.query.Limit)
+	for row, err := range c.RowsContext(ctx, params) {
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,114 @@
+package goe
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe/model"
+	"github.com/go-goe/goe/query/where"
+)
+
+type CompileAnimal struct {
+	Id        int
+	Name      string
+	Level     int8
+	CreatedAt time.Time
+	Tag       [16]byte
+}
+
+type CompileDatabase struct {
+	CompileAnimal *CompileAnimal
+	*DB
+}
+
+func TestCompile(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	db, err := Open[CompileDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	t.Run("Params", func(t *testing.T) {
+		// a value equal to other param is not replaced
+		compiled, err := Compile(Select(db.CompileAnimal).From(db.CompileAnimal).Wheres(
+			where.Equals(&db.CompileAnimal.Level, int8(-128)),
+			where.And(),
+			Param[int8]("level", where.Greater, &db.CompileAnimal.Level),
+			where.And(),
+			Param[time.Time]("created", where.Less, &db.CompileAnimal.CreatedAt),
+			where.And(),
+			Param[[16]byte]("tag", where.Equals, &db.CompileAnimal.Tag),
+			where.And(),
+			Param[string]("name", where.Like, &db.CompileAnimal.Name),
+		))
+		if err != nil {
+			t.Fatalf("Expected compile, got error: %v", err)
+		}
+
+		now, tag := time.Now(), [16]byte{1}
+		for _, level := range []int8{-128, 64, 127} {
+			_, err = compiled.AsSlice(Params{"level": level, "created": now, "tag": tag, "name": "%Cat%"})
+			if err != nil {
+				t.Fatalf("Expected compiled select, got error: %v", err)
+			}
+			if args := driver.lastQuery().Arguments; !slices.Equal(args, []any{int8(-128), level, now, tag, "%Cat%"}) {
+				t.Errorf("Expected the param values on arguments, got %v", args)
+			}
+		}
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		compiled, err := Compile(Select(db.CompileAnimal).From(db.CompileAnimal).
+			Wheres(Param[int]("id", where.Equals, &db.CompileAnimal.Id)))
+		if err != nil {
+			t.Fatalf("Expected compile, got error: %v", err)
+		}
+		for _, params := range []Params{{}, {"id": "1"}, {"id": int64(1)}, {"id": nil}} {
+			if _, err = compiled.AsSlice(params); err == nil || !strings.Contains(err.Error(), `"id"`) {
+				t.Errorf("Expected param error on %v, got %v", params, err)
+			}
+		}
+	})
+
+	t.Run("InvalidOperation", func(t *testing.T) {
+		attributeWhere := func(a *int, v int) model.Operation {
+			return where.EqualsArg[int](a, &db.CompileAnimal.Id)
+		}
+		_, err := Compile(Select(db.CompileAnimal).From(db.CompileAnimal).
+			Wheres(Param[int]("id", attributeWhere, &db.CompileAnimal.Id)))
+		if err == nil {
+			t.Errorf("Expected error on param without value, got nil")
+		}
+	})
+
+	t.Run("ToSQL", func(t *testing.T) {
+		_, args, err := Select(db.CompileAnimal).From(db.CompileAnimal).
+			Wheres(Param[int]("id", where.Equals, &db.CompileAnimal.Id)).ToSQL()
+		if err != nil {
+			t.Fatalf("Expected sql with param, got error: %v", err)
+		}
+		if len(args) != 1 || fmt.Sprint(args[0]) != `goe.Param("id")` {
+			t.Errorf("Expected the param on the arguments, got %v", args)
+		}
+	})
+
+	t.Run("OutsideCompile", func(t *testing.T) {
+		count := driver.queryCount()
+		_, err := Select(db.CompileAnimal).From(db.CompileAnimal).
+			Wheres(Param[int]("id", where.Equals, &db.CompileAnimal.Id)).AsSlice()
+		if err == nil {
+			t.Errorf("Expected error on param outside compile, got nil")
+		}
+		err = Delete(db.CompileAnimal).Wheres(Param[int]("id", where.Equals, &db.CompileAnimal.Id))
+		if err == nil {
+			t.Errorf("Expected error on param outside compile, got nil")
+		}
+		if driver.queryCount() != count {
+			t.Errorf("Expected no query with param, got %v queries", driver.queryCount()-count)
+		}
+	})
+}
@@ -1,43 +1,164 @@
 package goe
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/go-goe/goe/enum"
 	"github.com/go-goe/goe/model"
 )
 
+// tableRange is the memory of a mapped table struct, owned by db
+type tableRange struct {
+	start, end uintptr
+	db         *DB
+	table      unsafe.Pointer // keeps the table alive, so a dropped database memory is not reused by other table
+}
+
+// newTableRange returns the range of table, a pointer to a mapped struct
+func newTableRange(table reflect.Value, db *DB) tableRange {
+	start := table.Pointer()
+	return tableRange{start: start, end: start + max(table.Type().Elem().Size(), 1), db: db, table: table.UnsafePointer()}
+}
+
+// goeMap is the registry of the opened databases by the address of the mapped tables,
+// the queries find the fields on the database that owns the table. The reads use a
+// immutable snapshot sorted by address without locks and the writes, made on open
+// and close, replace the snapshot with a copy.
 type goeMap struct {
-	mu       sync.Mutex
-	mapField map[uintptr]field
+	mu       sync.Mutex // serializes the writes
+	snapshot atomic.Pointer[[]tableRange]
 }
 
-func (am *goeMap) get(key uintptr) field {
+// register publishes the tables of a opened database
+func (am *goeMap) register(db *DB) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	return am.mapField[key]
+
+	next := append(slices.Clone(am.load()), db.tables...)
+	slices.SortFunc(next, func(a, b tableRange) int { return cmp.Compare(a.start, b.start) })
+	am.snapshot.Store(&next)
 }
 
-func (am *goeMap) set(key uintptr, value field) {
+// unregister removes the tables of a closed database
+func (am *goeMap) unregister(db *DB) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	am.mapField[key] = value
+
+	next := slices.DeleteFunc(slices.Clone(am.load()), func(t tableRange) bool { return t.db == db })
+	am.snapshot.Store(&next)
 }
 
-func (am *goeMap) delete(key uintptr) {
-	am.mu.Lock()
-	defer am.mu.Unlock()
-	delete(am.mapField, key)
+// load returns the current snapshot, it must not be changed
+func (am *goeMap) load() []tableRange {
+	if tables := am.snapshot.Load(); tables != nil {
+		return *tables
+	}
+	return nil
+}
+
+// lookup returns the database of the table that contains addr
+func (am *goeMap) lookup(addr uintptr) *DB {
+	tables := am.load()
+	i, _ := slices.BinarySearchFunc(tables, addr, func(t tableRange, addr uintptr) int {
+		if addr < t.start {
+			return 1
+		}
+		if addr >= t.end {
+			return -1
+		}
+		return 0
+	})
+	if i < len(tables) && tables[i].start <= addr && addr < tables[i].end {
+		return tables[i].db
+	}
+	return nil
 }
 
-var addrMap *goeMap
+var addrMap = &goeMap{}
+
+// fieldsOf returns the mapped fields of the database that owns arg,
+// arg is a pointer to a table, to a field or to a struct of pointers to fields
+func fieldsOf(arg any) map[uintptr]field {
+	if db := findDb(reflect.ValueOf(arg), 2); db != nil {
+		return db.fields
+	}
+	return nil
+}
+
+func findDb(v reflect.Value, depth int) *DB {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil
+	}
+	if db := addrMap.lookup(uintptr(v.UnsafePointer())); db != nil {
+		return db
+	}
+	if depth == 0 || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	for i := range v.Elem().NumField() {
+		if db := findDb(v.Elem().Field(i), depth-1); db != nil {
+			return db
+		}
+	}
+	return nil
+}
 
 type DB struct {
-	driver Driver
+	driver   Driver
+	fields   map[uintptr]field // fields mapped by this database
+	tables   []tableRange
+	replicas []Driver
+	weights  []int // cumulative weights of the replicas
+	next     atomic.Uint64
+}
+
+func (db *DB) totalWeight() int {
+	if len(db.weights) == 0 {
+		return 0
+	}
+	return db.weights[len(db.weights)-1]
+}
+
+// readDriver returns the next replica by weighted round-robin,
+// or the primary if the database don't have replicas
+func (db *DB) readDriver() Driver {
+	if len(db.replicas) == 0 {
+		return db.driver
+	}
+
+	position := int((db.next.Add(1) - 1) % uint64(db.totalWeight()))
+	for i, weight := range db.weights {
+		if position < weight {
+			return db.replicas[i]
+		}
+	}
+	return db.driver
+}
+
+// readConfig returns the config used by the selects on the read driver, the config
+// of the primary with the explain and the error classify of the replica
+func (db *DB) readConfig(read Driver) *DatabaseConfig {
+	config := db.driver.GetDatabaseConfig()
+	if read == db.driver {
+		return config
+	}
+	replica := read.GetDatabaseConfig()
+	c := *config
+	c.databaseName = replica.databaseName
+	c.explain = replica.explain
+	c.classify = replica.classify
+	return &c
 }
 
 // Return the database stats as [sql.DBStats].
@@ -45,6 +166,25 @@ func (db *DB) Stats() sql.DBStats {
 	return db.driver.Stats()
 }
 
+// Return the stats of the prepared statement cache,
+// enabled by [DatabaseConfig.StatementCacheSize].
+// The stats are the sum of the primary and replicas caches,
+// a driver that is not a [StatementCacher] is not counted.
+func (db *DB) StatementStats() StatementStats {
+	var stats StatementStats
+	for _, driver := range append([]Driver{db.driver}, db.replicas...) {
+		if cacher, ok := driver.(StatementCacher); ok {
+			s := cacher.StatementStats()
+			stats.Size += s.Size
+			stats.MaxSize += s.MaxSize
+			stats.Hits += s.Hits
+			stats.Misses += s.Misses
+			stats.Evictions += s.Evictions
+		}
+	}
+	return stats
+}
+
 // Get the database name; SQLite, PostgreSQL...
 func (db *DB) Name() string {
 	return db.driver.Name()
@@ -53,7 +193,7 @@ func (db *DB) Name() string {
 func (db *DB) RawQueryContext(ctx context.Context, rawSql string, args ...any) (Rows, error) {
 	query := model.Query{Type: enum.RawQuery, RawSql: rawSql, Arguments: args}
 	var rows Rows
-	rows, query.Header.Err = wrapperQuery(ctx, db.driver.NewConnection(), &query)
+	rows, query.Header.Err = wrapperQuery(ctx, db.driver.NewConnection(), &query, db.driver.GetDatabaseConfig())
 	if query.Header.Err != nil {
 		return nil, db.driver.GetDatabaseConfig().ErrorQueryHandler(ctx, query)
 	}
@@ -63,7 +203,7 @@ func (db *DB) RawQueryContext(ctx context.Context, rawSql string, args ...any) (
 
 func (db *DB) RawExecContext(ctx context.Context, rawSql string, args ...any) error {
 	query := model.Query{Type: enum.RawQuery, RawSql: rawSql, Arguments: args}
-	query.Header.Err = wrapperExec(ctx, db.driver.NewConnection(), &query)
+	query.Header.Err = wrapperExec(ctx, db.driver.NewConnection(), &query, db.driver.GetDatabaseConfig())
 	if query.Header.Err != nil {
 		return db.driver.GetDatabaseConfig().ErrorQueryHandler(ctx, query)
 	}
@@ -87,10 +227,15 @@ func (db *DB) NewTransactionContext(ctx context.Context, isolation sql.Isolation
 	if err != nil {
 		return nil, db.driver.GetDatabaseConfig().ErrorHandler(ctx, err)
 	}
+	if db.driver.GetDatabaseConfig().Cache != nil {
+		return &transaction{Transaction: t, config: db.driver.GetDatabaseConfig()}, nil
+	}
 	return t, nil
 }
 
 // Closes the database connection.
+//
+// The mapped tables are kept in memory until Close, a database dropped without Close is not collected.
 func Close(dbTarget any) error {
 	goeDb := getDatabase(dbTarget)
 	err := goeDb.driver.Close()
@@ -98,27 +243,41 @@ func Close(dbTarget any) error {
 		return goeDb.driver.GetDatabaseConfig().ErrorHandler(context.TODO(), err)
 	}
 
-	valueOf := reflect.ValueOf(dbTarget).Elem()
-
-	for i := range valueOf.NumField() - 1 {
-		fieldOf := valueOf.Field(i).Elem()
-		for fieldId := range fieldOf.NumField() {
-			addrMap.delete(uintptr(fieldOf.Field(fieldId).Addr().UnsafePointer()))
+	for _, replica := range goeDb.replicas {
+		err = replica.Close()
+		if err != nil {
+			return replica.GetDatabaseConfig().ErrorHandler(context.TODO(), err)
 		}
 	}
 
+	addrMap.unregister(goeDb)
+	unregisterScanners(goeDb)
 	return nil
 }
 
 // Database config used by all GOE drivers
 type DatabaseConfig struct {
-	Logger           Logger
-	IncludeArguments bool          // include all arguments used on query
-	QueryThreshold   time.Duration // query threshold to warning on slow queries
-	databaseName     string
+	Logger             Logger
+	IncludeArguments   bool             // include all arguments used on query
+	QueryThreshold     time.Duration    // query threshold to warning on slow queries
+	ExplainSlowQueries bool             // include the query plan on the warning of slow queries
+	Tracer             Tracer           // receives a span around every query
+	Metrics            MetricsCollector // receives the metrics of every query
+	QueryTags          bool             // add the tags of the context as a sql comment on the queries
+	Redact             func(any) any    // replaces the arguments of sensitive attributes on logs, by default with "[REDACTED]"
+	Cache              Cache            // stores the results of the selects using Cache(ttl)
+	StatementCacheSize int              // max of prepared statements cached by the driver, 0 disables the cache
+	Codecs             []Codec          // converts custom types on arguments, scans and migrate
+	databaseName       string
+	explain            func(context.Context, *model.Query) (string, error)
+	classify           func(error) error
+	codecs             map[reflect.Type]*Codec
+	generations        *tableGenerations
+	explains           chan struct{} // running explains of slow queries
 }
 
 func (c DatabaseConfig) ErrorHandler(ctx context.Context, err error) error {
+	err = c.classifyError(err)
 	if c.Logger != nil {
 		c.Logger.ErrorContext(ctx, "error", "database", c.databaseName, "err", err)
 	}
@@ -126,13 +285,14 @@ func (c DatabaseConfig) ErrorHandler(ctx context.Context, err error) error {
 }
 
 func (c DatabaseConfig) ErrorQueryHandler(ctx context.Context, query model.Query) error {
+	query.Header.Err = c.classifyError(query.Header.Err)
 	if c.Logger == nil {
 		return query.Header.Err
 	}
 	logs := make([]any, 0)
 	logs = append(logs, "database", c.databaseName)
 	if c.IncludeArguments {
-		logs = append(logs, "arguments", query.Arguments)
+		logs = append(logs, "arguments", c.redactArguments(query))
 	}
 	logs = append(logs, "err", query.Header.Err)
 
@@ -150,18 +310,71 @@ func (c DatabaseConfig) InfoHandler(ctx context.Context, query model.Query) {
 	logs = append(logs, "database", c.databaseName)
 	logs = append(logs, "query_duration", qr)
 	if c.IncludeArguments {
-		logs = append(logs, "arguments", query.Arguments)
+		logs = append(logs, "arguments", c.redactArguments(query))
 	}
 	logs = append(logs, "sql", query.RawSql)
 
 	if c.QueryThreshold != 0 && qr > c.QueryThreshold {
 		c.Logger.WarnContext(ctx, "query_threshold", logs...)
+		if c.ExplainSlowQueries && c.explain != nil && query.Type != enum.RawQuery {
+			select {
+			case c.explains <- struct{}{}:
+				query.Arguments = slices.Clone(query.Arguments)
+				go c.explainQuery(ctx, query)
+			default:
+				// the max of explains is running, the plan is skipped
+			}
+		}
 		return
 	}
 
 	c.Logger.InfoContext(ctx, "query_runned", logs...)
 }
 
+// explainTimeout is the max time to get the plan of a slow query
+const explainTimeout = 10 * time.Second
+
+// maxExplains is the max of slow queries explained at the same time by a database,
+// the slow queries over the max are logged without the plan
+const maxExplains = 4
+
+// explainQuery logs the query plan of a slow query, it runs in background on a
+// new connection, so the rows or the transaction of the query are not held by the explain
+func (c DatabaseConfig) explainQuery(ctx context.Context, query model.Query) {
+	defer func() { <-c.explains }()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), explainTimeout)
+	defer cancel()
+
+	logs := []any{"database", c.databaseName, "sql", query.RawSql}
+	plan, err := c.explain(ctx, &query)
+	if err != nil {
+		logs = append(logs, "query_plan_err", err)
+	} else {
+		logs = append(logs, "query_plan", plan)
+	}
+	c.Logger.WarnContext(ctx, "query_plan", logs...)
+}
+
+// redactArguments returns a copy of the query arguments with the sensitive ones redacted
+func (c DatabaseConfig) redactArguments(query model.Query) []any {
+	if len(query.SensitiveArguments) == 0 {
+		return query.Arguments
+	}
+
+	arguments := slices.Clone(query.Arguments)
+	for _, i := range query.SensitiveArguments {
+		if i >= len(arguments) {
+			continue
+		}
+		if c.Redact != nil {
+			arguments[i] = c.Redact(arguments[i])
+			continue
+		}
+		arguments[i] = "[REDACTED]"
+	}
+	return arguments
+}
+
 func getDatabase(dbTarget any) *DB {
 	valueOf := reflect.ValueOf(dbTarget).Elem()
 	return valueOf.Field(valueOf.NumField() - 1).Interface().(*DB)
@@ -0,0 +1,94 @@
+package goe
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/go-goe/goe/query/where"
+)
+
+func TestDatabaseRegistry(t *testing.T) {
+	first, second := newFakeDriver("first"), newFakeDriver("second")
+	firstDb, err := Open[CommentDatabase](first)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(firstDb)
+	secondDb, err := Open[CommentDatabase](second)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+
+	for _, db := range []*CommentDatabase{firstDb, secondDb, firstDb} {
+		if _, err = Select(db.CommentAnimal).From(db.CommentAnimal).Wheres(where.Equals(&db.CommentAnimal.Id, 1)).AsSlice(); err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+	}
+	if first.queryCount() != 2 || second.queryCount() != 1 {
+		t.Errorf("Expected each select on the driver of the table, got %v and %v", first.queryCount(), second.queryCount())
+	}
+
+	_, err = Select(firstDb.CommentAnimal).From(firstDb.CommentAnimal).Wheres(where.Equals(&secondDb.CommentAnimal.Id, 1)).AsSlice()
+	if err == nil {
+		t.Errorf("Expected error on where of other database, got nil")
+	}
+
+	if db := addrMap.lookup(uintptr(unsafe.Pointer(&secondDb.CommentAnimal.Name))); db != secondDb.DB {
+		t.Errorf("Expected the field on the second database, got %v", db)
+	}
+	Close(secondDb)
+	if db := addrMap.lookup(uintptr(unsafe.Pointer(&secondDb.CommentAnimal.Name))); db != nil {
+		t.Errorf("Expected no database after close, got %v", db)
+	}
+	if err = Delete(secondDb.CommentAnimal).Wheres(); err == nil {
+		t.Errorf("Expected error on delete of closed database, got nil")
+	}
+	if _, err = Select(secondDb.CommentAnimal).From(secondDb.CommentAnimal).AsSlice(); err == nil {
+		t.Errorf("Expected error on select of closed database, got nil")
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			db, err := Open[CommentDatabase](newFakeDriver("race"))
+			if err != nil {
+				t.Errorf("Expected open, got error: %v", err)
+				return
+			}
+			Close(db)
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := Select(firstDb.CommentAnimal).From(firstDb.CommentAnimal).AsSlice(); err != nil {
+				t.Errorf("Expected select, got error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDatabaseRegistryKeepsTables(t *testing.T) {
+	finalized := make(chan struct{})
+	func() {
+		// a database dropped without Close stays registered
+		db, err := Open[CommentDatabase](newFakeDriver("dropped"))
+		if err != nil {
+			t.Fatalf("Expected open, got error: %v", err)
+		}
+		runtime.SetFinalizer(db.CommentAnimal, func(*CommentAnimal) { close(finalized) })
+	}()
+
+	for range 5 {
+		runtime.GC()
+	}
+	select {
+	case <-finalized:
+		t.Errorf("Expected the table of a registered database alive, got the table collected")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -2,16 +2,18 @@ package goe
 
 import (
 	"context"
+	"errors"
+	"reflect"
 
 	"github.com/go-goe/goe/enum"
 	"github.com/go-goe/goe/model"
-	"github.com/go-goe/goe/query/where"
 )
 
 type stateDelete struct {
 	conn    Connection
 	builder builder
 	ctx     context.Context
+	fields  map[uintptr]field // fields of the database that owns the table
 	err     error
 }
 
@@ -55,7 +57,7 @@ func (r *remove[T]) OnErrNotFound(err error) *remove[T] {
 
 func (r *remove[T]) ById(value T) error {
 	pks, valuesPks, err := getArgsPks(getArgs{
-		addrMap:     addrMap.mapField,
+		addrMap:     r.delete.fields,
 		table:       r.table,
 		value:       value,
 		errNotFound: r.errNotFound})
@@ -64,18 +66,26 @@ func (r *remove[T]) ById(value T) error {
 		return err
 	}
 
-	if _, err := Find(r.table).OnErrNotFound(r.errNotFound).OnTransaction(r.tx).ById(value); err != nil {
+	if _, err := Find(r.table).OnPrimary().OnErrNotFound(r.errNotFound).OnTransaction(r.tx).ById(value); err != nil {
 		return err
 	}
 
-	brs := make([]model.Operation, 0, len(pks))
-	brs = append(brs, where.Equals(&pks[0], valuesPks[0]))
-	for i := 1; i < len(pks); i++ {
-		brs = append(brs, where.And())
-		brs = append(brs, where.Equals(&pks[i], valuesPks[i]))
+	return r.delete.Wheres(equalsOperations(pks, valuesPks)...)
+}
+
+// ToSQL returns the sql and arguments of [remove.ById] without running the query.
+func (r *remove[T]) ToSQL(value T) (string, []any, error) {
+	pks, valuesPks, err := getArgsPks(getArgs{
+		addrMap:     r.delete.fields,
+		table:       r.table,
+		value:       value,
+		errNotFound: r.errNotFound})
+
+	if err != nil {
+		return "", nil, err
 	}
 
-	return r.delete.Wheres(brs...)
+	return r.delete.ToSQL(equalsOperations(pks, valuesPks)...)
 }
 
 // Delete remove records in the given table
@@ -98,7 +108,13 @@ func Delete[T any](table *T) *stateDelete {
 // See [Delete] for examples
 func DeleteContext[T any](ctx context.Context, table *T) *stateDelete {
 	var state *stateDelete = createDeleteState(ctx)
-	state.builder.fields = append(state.builder.fields, getArg(table, addrMap.mapField, nil))
+	state.fields = fieldsOf(table)
+	field := tableField(reflect.ValueOf(table), state.fields)
+	if field == nil {
+		state.err = errors.New("goe: invalid argument. try sending a pointer to a database mapped struct as argument")
+		return state
+	}
+	state.builder.fields = append(state.builder.fields, field)
 	return state
 }
 
@@ -113,7 +129,7 @@ func (s *stateDelete) Wheres(brs ...model.Operation) error {
 		return s.err
 	}
 
-	s.err = helperWhere(&s.builder, addrMap.mapField, brs...)
+	s.err = helperWhere(&s.builder, s.fields, brs...)
 	if s.err != nil {
 		return s.err
 	}
@@ -128,6 +144,21 @@ func (s *stateDelete) Wheres(brs ...model.Operation) error {
 	return handlerValues(s.ctx, s.conn, s.builder.query, driver.GetDatabaseConfig())
 }
 
+// ToSQL returns the sql and arguments of the delete with the where operations, without running the query.
+func (s *stateDelete) ToSQL(brs ...model.Operation) (string, []any, error) {
+	if s.err != nil {
+		return "", nil, s.err
+	}
+
+	b := s.builder.copy()
+	if err := helperWhere(&b, s.fields, brs...); err != nil {
+		return "", nil, err
+	}
+	b.buildSqlDelete()
+
+	return render(b.fields[0].getDb().driver, &b.query)
+}
+
 func createDeleteState(ctx context.Context) *stateDelete {
 	return &stateDelete{builder: createBuilder(enum.DeleteQuery), ctx: ctx}
 }
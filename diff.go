@@ -0,0 +1,375 @@
+package goe
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SchemaDiff is the difference between the mapped structs and the database schema.
+type SchemaDiff struct {
+	MissingTables []string // mapped tables not found on database
+	ExtraTables   []string // database tables without a mapped struct
+	Tables        []TableDiff
+}
+
+// TableDiff is the difference of a table present on both, structs and database.
+type TableDiff struct {
+	Table              string
+	MissingColumns     []string
+	ExtraColumns       []string
+	ChangedColumns     []ColumnDiff
+	MissingIndexes     []string
+	ExtraIndexes       []string
+	ChangedIndexes     []string
+	MissingForeignKeys []string
+	ExtraForeignKeys   []string
+	MissingChecks      []string
+	ExtraChecks        []string
+	ChangedChecks      []string
+}
+
+// ColumnDiff is a column with a different data type, nullability or default.
+type ColumnDiff struct {
+	Column   string
+	Expected AttributeMigrate
+	Actual   AttributeMigrate
+}
+
+// Empty reports if the database matches the mapped structs.
+func (d SchemaDiff) Empty() bool {
+	return len(d.MissingTables) == 0 && len(d.ExtraTables) == 0 && len(d.Tables) == 0
+}
+
+func (d SchemaDiff) String() string {
+	b := strings.Builder{}
+	for _, t := range d.MissingTables {
+		fmt.Fprintf(&b, "missing table %q\n", t)
+	}
+	for _, t := range d.ExtraTables {
+		fmt.Fprintf(&b, "extra table %q\n", t)
+	}
+	for _, t := range d.Tables {
+		for _, c := range t.MissingColumns {
+			fmt.Fprintf(&b, "%v: missing column %q\n", t.Table, c)
+		}
+		for _, c := range t.ExtraColumns {
+			fmt.Fprintf(&b, "%v: extra column %q\n", t.Table, c)
+		}
+		for _, c := range t.ChangedColumns {
+			fmt.Fprintf(&b, "%v: column %q expected %v got %v\n", t.Table, c.Column, columnString(c.Expected), columnString(c.Actual))
+		}
+		for _, i := range t.MissingIndexes {
+			fmt.Fprintf(&b, "%v: missing index %q\n", t.Table, i)
+		}
+		for _, i := range t.ExtraIndexes {
+			fmt.Fprintf(&b, "%v: extra index %q\n", t.Table, i)
+		}
+		for _, i := range t.ChangedIndexes {
+			fmt.Fprintf(&b, "%v: changed index %q\n", t.Table, i)
+		}
+		for _, f := range t.MissingForeignKeys {
+			fmt.Fprintf(&b, "%v: missing foreign key %v\n", t.Table, f)
+		}
+		for _, f := range t.ExtraForeignKeys {
+			fmt.Fprintf(&b, "%v: extra foreign key %v\n", t.Table, f)
+		}
+		for _, c := range t.MissingChecks {
+			fmt.Fprintf(&b, "%v: missing check %q\n", t.Table, c)
+		}
+		for _, c := range t.ExtraChecks {
+			fmt.Fprintf(&b, "%v: extra check %q\n", t.Table, c)
+		}
+		for _, c := range t.ChangedChecks {
+			fmt.Fprintf(&b, "%v: changed check %q\n", t.Table, c)
+		}
+	}
+	return b.String()
+}
+
+func (t TableDiff) empty() bool {
+	return len(t.MissingColumns) == 0 && len(t.ExtraColumns) == 0 && len(t.ChangedColumns) == 0 &&
+		len(t.MissingIndexes) == 0 && len(t.ExtraIndexes) == 0 && len(t.ChangedIndexes) == 0 &&
+		len(t.MissingForeignKeys) == 0 && len(t.ExtraForeignKeys) == 0 &&
+		len(t.MissingChecks) == 0 && len(t.ExtraChecks) == 0 && len(t.ChangedChecks) == 0
+}
+
+// Diff compares the mapped structs with the current database schema.
+//
+// Diff uses [context.Background] internally;
+// to specify the context, use [DiffContext].
+//
+// # Example
+//
+//	// fail fast on schema drift
+//	diff, err := goe.Diff(db)
+//	if err != nil {
+//		// handler error
+//	}
+//	if !diff.Empty() {
+//		panic(diff.String())
+//	}
+func Diff(dbTarget any) (*SchemaDiff, error) {
+	return DiffContext(context.Background(), dbTarget)
+}
+
+// DiffContext compares the mapped structs with the current database schema.
+//
+// See [Diff] for examples.
+func DiffContext(ctx context.Context, dbTarget any) (*SchemaDiff, error) {
+	db := getDatabase(dbTarget)
+
+	m := migrateFrom(dbTarget, db.driver)
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	introspector, ok := db.driver.(Introspector)
+	if !ok {
+		return nil, unsupported(db.driver, "Diff")
+	}
+	current, err := introspector.IntrospectContext(ctx)
+	if err != nil {
+		return nil, db.driver.GetDatabaseConfig().ErrorHandler(ctx, err)
+	}
+
+	return diffMigrator(m, current, introspector), nil
+}
+
+func diffMigrator(expected, actual *Migrator, driver Introspector) *SchemaDiff {
+	diff := new(SchemaDiff)
+	for _, name := range sortedTables(expected) {
+		current, ok := actual.Tables[name]
+		if !ok {
+			diff.MissingTables = append(diff.MissingTables, name)
+			continue
+		}
+		if t := diffTable(expected.Tables[name], current, driver); !t.empty() {
+			diff.Tables = append(diff.Tables, t)
+		}
+	}
+
+	for _, name := range sortedTables(actual) {
+		if _, ok := expected.Tables[name]; !ok {
+			diff.ExtraTables = append(diff.ExtraTables, name)
+		}
+	}
+	return diff
+}
+
+func diffTable(expected, actual *TableMigrate, driver Introspector) TableDiff {
+	diff := TableDiff{Table: expected.Name}
+
+	actualColumns := columnsMigrate(actual)
+	for _, c := range columnsMigrate(expected) {
+		i := slices.IndexFunc(actualColumns, func(a AttributeMigrate) bool { return a.Name == c.Name })
+		if i == -1 {
+			diff.MissingColumns = append(diff.MissingColumns, c.Name)
+			continue
+		}
+		if c.DataType == "" {
+			// auto increment primary keys are created with driver specific types
+			continue
+		}
+		c.DataType = driver.DataType(c.DataType)
+		if c.Nullable != actualColumns[i].Nullable || !strings.EqualFold(c.DataType, actualColumns[i].DataType) ||
+			!sameExpression(c.Default, actualColumns[i].Default) {
+			diff.ChangedColumns = append(diff.ChangedColumns, ColumnDiff{Column: c.Name, Expected: c, Actual: actualColumns[i]})
+		}
+	}
+
+	expectedColumns := columnsMigrate(expected)
+	for _, c := range actualColumns {
+		if !slices.ContainsFunc(expectedColumns, func(e AttributeMigrate) bool { return e.Name == c.Name }) {
+			diff.ExtraColumns = append(diff.ExtraColumns, c.Name)
+		}
+	}
+
+	for _, in := range expected.Indexes {
+		i := slices.IndexFunc(actual.Indexes, func(a IndexMigrate) bool { return a.Name == in.Name })
+		if i == -1 {
+			diff.MissingIndexes = append(diff.MissingIndexes, in.Name)
+			continue
+		}
+		if !sameIndex(in, actual.Indexes[i]) {
+			diff.ChangedIndexes = append(diff.ChangedIndexes, in.Name)
+		}
+	}
+	for _, in := range actual.Indexes {
+		if !slices.ContainsFunc(expected.Indexes, func(e IndexMigrate) bool { return e.Name == in.Name }) {
+			diff.ExtraIndexes = append(diff.ExtraIndexes, in.Name)
+		}
+	}
+
+	for _, check := range expected.Checks {
+		i := slices.IndexFunc(actual.Checks, func(a CheckMigrate) bool { return a.Name == check.Name })
+		if i == -1 {
+			diff.MissingChecks = append(diff.MissingChecks, check.Name)
+			continue
+		}
+		if !sameExpression(check.Expression, actual.Checks[i].Expression) {
+			diff.ChangedChecks = append(diff.ChangedChecks, check.Name)
+		}
+	}
+	for _, check := range actual.Checks {
+		if !slices.ContainsFunc(expected.Checks, func(e CheckMigrate) bool { return e.Name == check.Name }) {
+			diff.ExtraChecks = append(diff.ExtraChecks, check.Name)
+		}
+	}
+
+	expectedFks, actualFks := foreignKeysMigrate(expected), foreignKeysMigrate(actual)
+	for _, fk := range expectedFks {
+		if !slices.Contains(actualFks, fk) {
+			diff.MissingForeignKeys = append(diff.MissingForeignKeys, fk)
+		}
+	}
+	for _, fk := range actualFks {
+		if !slices.Contains(expectedFks, fk) {
+			diff.ExtraForeignKeys = append(diff.ExtraForeignKeys, fk)
+		}
+	}
+	return diff
+}
+
+func sameIndex(expected, actual IndexMigrate) bool {
+	if expected.Unique != actual.Unique || len(expected.Attributes) != len(actual.Attributes) ||
+		!sameIndexMethod(expected.Method, actual.Method) || !sameExpression(expected.Where, actual.Where) {
+		return false
+	}
+	for i := range expected.Attributes {
+		if expected.Attributes[i].Name != actual.Attributes[i].Name {
+			return false
+		}
+		e, a := indexColumn(expected, i), indexColumn(actual, i)
+		if e.Descending != a.Descending || !sameExpression(e.Expression, a.Expression) {
+			return false
+		}
+	}
+	return slices.EqualFunc(expected.Include, actual.Include, func(e, a AttributeMigrate) bool {
+		return e.Name == a.Name
+	})
+}
+
+// indexColumn returns the options of the attribute i, the options are empty if not declared
+func indexColumn(in IndexMigrate, i int) IndexColumnMigrate {
+	if i < len(in.Columns) {
+		return in.Columns[i]
+	}
+	return IndexColumnMigrate{}
+}
+
+// sameIndexMethod compares the methods, a empty method is the database default
+func sameIndexMethod(expected, actual string) bool {
+	if expected == "" {
+		return actual == "" || strings.EqualFold(actual, "btree")
+	}
+	return strings.EqualFold(expected, actual)
+}
+
+var (
+	castPattern  = regexp.MustCompile(`::(character varying|double precision|timestamp with(out)? time zone|\w+)(\([0-9, ]*\))?`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// sameExpression compares sql expressions as default, check and where values, ignoring case, spaces,
+// identifier quotes, outer parentheses and the casts added by the database outside of string literals
+func sameExpression(expected, actual string) bool {
+	return normalizeExpression(expected) == normalizeExpression(actual)
+}
+
+func normalizeExpression(expression string) string {
+	// the odd parts are inside of string literals and are kept as declared
+	parts := strings.Split(expression, "'")
+	for i := 0; i < len(parts); i += 2 {
+		part := strings.ToLower(parts[i])
+		part = castPattern.ReplaceAllString(part, "")
+		part = spacePattern.ReplaceAllString(part, "")
+		parts[i] = strings.NewReplacer(`"`, "", "`", "").Replace(part)
+	}
+	expression = strings.Join(parts, "'")
+	for len(expression) > 1 && expression[0] == '(' && closingParenthesis(expression) == len(expression)-1 {
+		expression = expression[1 : len(expression)-1]
+	}
+	return expression
+}
+
+// closingParenthesis returns the position of the parenthesis that closes the first one
+func closingParenthesis(expression string) int {
+	depth := 0
+	for i, c := range expression {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// columnsMigrate returns all the columns of the table,
+// auto increment primary keys are returned without data type
+func columnsMigrate(t *TableMigrate) []AttributeMigrate {
+	columns := make([]AttributeMigrate, 0, len(t.PrimaryKeys)+len(t.Attributes)+len(t.ManyToOnes)+len(t.OneToOnes))
+	for _, pk := range t.PrimaryKeys {
+		c := AttributeMigrate{Name: pk.Name, EscapingName: pk.EscapingName, DataType: pk.DataType, Default: pk.Default}
+		if pk.AutoIncrement {
+			c.DataType = ""
+		}
+		columns = append(columns, c)
+	}
+	columns = append(columns, t.Attributes...)
+	for _, mto := range t.ManyToOnes {
+		columns = append(columns, mto.AttributeMigrate)
+	}
+	for _, oto := range t.OneToOnes {
+		columns = append(columns, oto.AttributeMigrate)
+	}
+	return columns
+}
+
+func foreignKeysMigrate(t *TableMigrate) []string {
+	fks := make([]string, 0, len(t.ManyToOnes)+len(t.OneToOnes))
+	for _, mto := range t.ManyToOnes {
+		fks = append(fks, foreignKeyString(mto.Name, mto.TargetTable, mto.TargetColumn, mto.OnDelete, mto.OnUpdate))
+	}
+	for _, oto := range t.OneToOnes {
+		fks = append(fks, foreignKeyString(oto.Name, oto.TargetTable, oto.TargetColumn, oto.OnDelete, oto.OnUpdate))
+	}
+	return fks
+}
+
+func foreignKeyString(column, table, targetColumn, onDelete, onUpdate string) string {
+	if onDelete == "" {
+		onDelete = "NO ACTION"
+	}
+	if onUpdate == "" {
+		onUpdate = "NO ACTION"
+	}
+	return fmt.Sprintf("%v -> %v.%v on delete %v on update %v", column, table, targetColumn, onDelete, onUpdate)
+}
+
+func columnString(a AttributeMigrate) string {
+	s := a.DataType + " not null"
+	if a.Nullable {
+		s = a.DataType + " null"
+	}
+	if a.Default != "" {
+		s += " default " + a.Default
+	}
+	return s
+}
+
+func sortedTables(m *Migrator) []string {
+	tables := make([]string, 0, len(m.Tables))
+	for name := range m.Tables {
+		tables = append(tables, name)
+	}
+	slices.Sort(tables)
+	return tables
+}
@@ -0,0 +1,119 @@
+package goe
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+type dataTypeIntrospector struct{}
+
+func (dataTypeIntrospector) IntrospectContext(context.Context) (*Migrator, error) { return nil, nil }
+func (dataTypeIntrospector) DataType(dataType string) string                      { return dataType }
+
+func TestSameIndex(t *testing.T) {
+	name := AttributeMigrate{Name: "name"}
+	email := AttributeMigrate{Name: "email"}
+	index := IndexMigrate{Name: "users_idx", Attributes: []AttributeMigrate{name}, Columns: []IndexColumnMigrate{{}}}
+
+	testCases := []struct {
+		desc   string
+		change func(in *IndexMigrate)
+		same   bool
+	}{
+		{desc: "Equal", change: func(in *IndexMigrate) {}, same: true},
+		{desc: "DefaultMethod", change: func(in *IndexMigrate) { in.Method = "btree" }, same: true},
+		{desc: "Method", change: func(in *IndexMigrate) { in.Method = "hash" }, same: false},
+		{desc: "Unique", change: func(in *IndexMigrate) { in.Unique = true }, same: false},
+		{desc: "Where", change: func(in *IndexMigrate) { in.Where = "deleted_at IS NULL" }, same: false},
+		{desc: "Descending", change: func(in *IndexMigrate) { in.Columns = []IndexColumnMigrate{{Descending: true}} }, same: false},
+		{desc: "Expression", change: func(in *IndexMigrate) { in.Columns = []IndexColumnMigrate{{Expression: "lower(name)"}} }, same: false},
+		{desc: "Include", change: func(in *IndexMigrate) { in.Include = []AttributeMigrate{email} }, same: false},
+		{desc: "Columns", change: func(in *IndexMigrate) { in.Attributes = []AttributeMigrate{email} }, same: false},
+		{desc: "MissingColumnOptions", change: func(in *IndexMigrate) { in.Columns = nil }, same: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual := index
+			tC.change(&actual)
+			if got := sameIndex(index, actual); got != tC.same {
+				t.Errorf("Expected sameIndex %v, got %v", tC.same, got)
+			}
+		})
+	}
+
+	partial := IndexMigrate{Name: "users_idx", Where: `"deleted_at" IS NULL`, Attributes: []AttributeMigrate{name},
+		Columns: []IndexColumnMigrate{{Expression: `lower("name")`}}}
+	introspected := IndexMigrate{Name: "users_idx", Method: "btree", Where: "(deleted_at IS NULL)", Attributes: []AttributeMigrate{name},
+		Columns: []IndexColumnMigrate{{Expression: "LOWER(name)"}}}
+	if !sameIndex(partial, introspected) {
+		t.Errorf("Expected the same index after the database normalization")
+	}
+}
+
+func TestSameExpression(t *testing.T) {
+	testCases := []struct {
+		expected, actual string
+		same             bool
+	}{
+		{expected: "'active'", actual: "'active'::character varying", same: true},
+		{expected: "CURRENT_TIMESTAMP", actual: "current_timestamp", same: true},
+		{expected: "age > 18", actual: "((age > 18))", same: true},
+		{expected: "(a > 1) AND (b > 1)", actual: "a > 1 AND b > 1", same: false},
+		{expected: "'Active'", actual: "'active'", same: false},
+		{expected: "'a b'", actual: "'ab'", same: false},
+		{expected: "0", actual: "1", same: false},
+		{expected: "", actual: "0", same: false},
+	}
+	for _, tC := range testCases {
+		if got := sameExpression(tC.expected, tC.actual); got != tC.same {
+			t.Errorf("Expected sameExpression(%q, %q) %v, got %v", tC.expected, tC.actual, tC.same, got)
+		}
+	}
+}
+
+func TestDiffDefaultsAndChecks(t *testing.T) {
+	expected := &Migrator{Tables: map[string]*TableMigrate{"users": {
+		Name:       "users",
+		Attributes: []AttributeMigrate{{Name: "status", DataType: "text", Default: "'active'"}, {Name: "age", DataType: "int"}},
+		Checks: []CheckMigrate{
+			{Name: "users_age_check", Expression: "age >= 0"},
+			{Name: "users_status_check", Expression: "status <> ''"},
+		},
+	}}}
+	actual := &Migrator{Tables: map[string]*TableMigrate{"users": {
+		Name:       "users",
+		Attributes: []AttributeMigrate{{Name: "status", DataType: "text", Default: "'inactive'::text"}, {Name: "age", DataType: "int"}},
+		Checks: []CheckMigrate{
+			{Name: "users_age_check", Expression: "(age > 0)"},
+			{Name: "users_old_check", Expression: "age < 200"},
+		},
+	}}}
+
+	diff := diffMigrator(expected, actual, dataTypeIntrospector{})
+	if len(diff.Tables) != 1 {
+		t.Fatalf("Expected one changed table, got: %v", diff)
+	}
+	table := diff.Tables[0]
+	if len(table.ChangedColumns) != 1 || table.ChangedColumns[0].Column != "status" {
+		t.Errorf("Expected changed default of status, got: %v", table.ChangedColumns)
+	}
+	if !slices.Equal(table.ChangedChecks, []string{"users_age_check"}) {
+		t.Errorf("Expected changed check users_age_check, got: %v", table.ChangedChecks)
+	}
+	if !slices.Equal(table.MissingChecks, []string{"users_status_check"}) {
+		t.Errorf("Expected missing check users_status_check, got: %v", table.MissingChecks)
+	}
+	if !slices.Equal(table.ExtraChecks, []string{"users_old_check"}) {
+		t.Errorf("Expected extra check users_old_check, got: %v", table.ExtraChecks)
+	}
+
+	actual.Tables["users"].Attributes[0].Default = "'active'::text"
+	actual.Tables["users"].Checks = []CheckMigrate{
+		{Name: "users_age_check", Expression: "(age >= 0)"},
+		{Name: "users_status_check", Expression: "(status <> ''::text)"},
+	}
+	if diff = diffMigrator(expected, actual, dataTypeIntrospector{}); !diff.Empty() {
+		t.Errorf("Expected no drift, got: %v", diff)
+	}
+}
@@ -0,0 +1,188 @@
+package goe
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/go-goe/goe/model"
+)
+
+// fakeDriver is a in memory driver used by the tests, it records the
+// queries and returns the same rows on every select
+type fakeDriver struct {
+	name    string
+	config  DatabaseConfig
+	mu      sync.Mutex
+	queries []model.Query
+	rows    [][]any
+	err     error                      // returned by the queries
+	rowsErr error                      // returned by the rows after the iteration
+	result  func(*model.Query) [][]any // returns the rows of the query in place of rows
+	initErr error
+	closed  bool
+}
+
+func newFakeDriver(name string) *fakeDriver {
+	return &fakeDriver{name: name}
+}
+
+func (d *fakeDriver) MigrateContext(context.Context, *Migrator) error       { return nil }
+func (d *fakeDriver) DropTable(string) error                                { return nil }
+func (d *fakeDriver) DropColumn(table, column string) error                 { return nil }
+func (d *fakeDriver) RenameColumn(table, oldColumn, newColumn string) error { return nil }
+func (d *fakeDriver) Init() error                                           { return d.initErr }
+func (d *fakeDriver) KeywordHandler(s string) string                        { return `"` + s + `"` }
+func (d *fakeDriver) NewConnection() Connection                             { return fakeConn{driver: d} }
+func (d *fakeDriver) Stats() sql.DBStats                                    { return sql.DBStats{} }
+func (d *fakeDriver) Name() string                                          { return d.name }
+func (d *fakeDriver) GetDatabaseConfig() *DatabaseConfig                    { return &d.config }
+
+func (d *fakeDriver) NewTransaction(ctx context.Context, opts *sql.TxOptions) (Transaction, error) {
+	return &fakeTx{fakeConn: fakeConn{driver: d}}, nil
+}
+
+func (d *fakeDriver) Close() error {
+	d.closed = true
+	return nil
+}
+
+func (d *fakeDriver) Render(query *model.Query) (string, []any) {
+	return fmt.Sprintf("%v %v %v %v %v", query.Type, query.Tables, query.Attributes, query.Joins, query.WhereOperations), query.Arguments
+}
+
+// queryCount returns the number of queries runned on the driver
+func (d *fakeDriver) queryCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queries)
+}
+
+// lastQuery returns the last query runned on the driver
+func (d *fakeDriver) lastQuery() model.Query {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queries[len(d.queries)-1]
+}
+
+func (d *fakeDriver) record(query *model.Query) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	q := *query
+	q.Arguments = slices.Clone(query.Arguments)
+	d.queries = append(d.queries, q)
+}
+
+func (d *fakeDriver) rowsOf(query *model.Query) [][]any {
+	if d.result != nil {
+		return d.result(query)
+	}
+	return d.rows
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query *model.Query) error {
+	c.driver.record(query)
+	return c.driver.err
+}
+
+func (c fakeConn) QueryRowContext(ctx context.Context, query *model.Query) Row {
+	c.driver.record(query)
+	if c.driver.err != nil {
+		return errRow{err: c.driver.err}
+	}
+	rows := c.driver.rowsOf(query)
+	if len(rows) == 0 {
+		return errRow{err: sql.ErrNoRows}
+	}
+	return &fakeRows{rows: rows[:1], i: 1}
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query *model.Query) (Rows, error) {
+	c.driver.record(query)
+	if c.driver.err != nil {
+		return nil, c.driver.err
+	}
+	return &fakeRows{rows: c.driver.rowsOf(query), err: c.driver.rowsErr}, nil
+}
+
+type fakeTx struct {
+	fakeConn
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Commit() error {
+	t.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.rolledBack = true
+	return nil
+}
+
+type fakeRows struct {
+	rows   [][]any
+	i      int
+	closed bool
+	err    error
+}
+
+func (r *fakeRows) Err() error {
+	return r.err
+}
+
+func (r *fakeRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.rows[r.i-1]
+	if len(dest) != len(row) {
+		return fmt.Errorf("expected %v destination arguments in Scan, not %v", len(row), len(dest))
+	}
+	for i := range dest {
+		if err := assign(dest[i], row[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assign sets src on the pointer dest, like the database/sql scan
+func assign(dest, src any) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+	v := reflect.ValueOf(dest).Elem()
+	if src == nil {
+		v.SetZero()
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := assign(ptr.Interface(), src); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if !reflect.TypeOf(src).ConvertibleTo(v.Type()) || (reflect.TypeOf(src).Kind() == reflect.String) != (v.Kind() == reflect.String) {
+		return fmt.Errorf("converting %T to %v is unsupported", src, v.Type())
+	}
+	v.Set(reflect.ValueOf(src).Convert(v.Type()))
+	return nil
+}
@@ -0,0 +1,142 @@
+package goe
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-goe/goe/query/where"
+)
+
+type EmbeddedBase struct {
+	Id   int
+	Name string
+}
+
+type EmbeddedAudit struct {
+	Name    string
+	Version int
+}
+
+type EmbeddedAddress struct {
+	Street string
+	City   string
+}
+
+type EmbeddedNode struct {
+	Id int
+	*EmbeddedNode
+}
+
+type EmbeddedPerson struct {
+	*EmbeddedBase
+	EmbeddedAudit
+	Home    EmbeddedAddress  `goe:"embedded;prefix:home_"`
+	Work    *EmbeddedAddress `goe:"embedded;prefix:work_"`
+	Version int
+}
+
+type EmbeddedAmbiguous struct {
+	Id int
+	EmbeddedAddress
+	Other struct {
+		Street string
+	} `goe:"embedded"`
+}
+
+type EmbeddedDatabase struct {
+	EmbeddedPerson *EmbeddedPerson
+	*DB
+}
+
+func TestStructFields(t *testing.T) {
+	names := func(typeOf reflect.Type) []string {
+		var names []string
+		for _, f := range structFields(typeOf) {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	testCases := []struct {
+		desc   string
+		typeOf reflect.Type
+		want   []string
+	}{
+		// Name is ambiguous between Base and Audit, Version of Audit is shadowed by Person
+		{desc: "Promotion", typeOf: reflect.TypeFor[EmbeddedPerson](), want: []string{"Id", "HomeStreet", "HomeCity", "WorkStreet", "WorkCity", "Version"}},
+		{desc: "Ambiguous", typeOf: reflect.TypeFor[EmbeddedAmbiguous](), want: []string{"Id", "City"}},
+		{desc: "Cycle", typeOf: reflect.TypeFor[EmbeddedNode](), want: []string{"Id"}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if got := names(tC.typeOf); !slices.Equal(got, tC.want) {
+				t.Errorf("Expected fields %v, got %v", tC.want, got)
+			}
+		})
+	}
+
+	first, second := structFields(reflect.TypeFor[EmbeddedPerson]()), structFields(reflect.TypeFor[EmbeddedPerson]())
+	if &first[0] != &second[0] {
+		t.Errorf("Expected the fields cached by type")
+	}
+}
+
+func TestEmbeddedPointer(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	db, err := Open[EmbeddedDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	t.Run("Insert", func(t *testing.T) {
+		driver.rows = [][]any{{7}}
+		defer func() { driver.rows = nil }()
+
+		person := EmbeddedPerson{Home: EmbeddedAddress{City: "Springfield"}, Version: 2}
+		if err := Insert(db.EmbeddedPerson).One(&person); err != nil {
+			t.Fatalf("Expected insert, got error: %v", err)
+		}
+		if person.EmbeddedBase == nil || person.Id != 7 {
+			t.Errorf("Expected the id on the embedded pointer, got %+v", person.EmbeddedBase)
+		}
+		if args := driver.lastQuery().Arguments; !slices.Contains(args, any("Springfield")) || len(args) != 5 {
+			t.Errorf("Expected the nil work address as zero values, got %v", args)
+		}
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		driver.rows = [][]any{{1, "a", "b", "c", "d", 1}, {2, "e", "f", "g", "h", 2}}
+		defer func() { driver.rows = nil }()
+
+		persons, err := Select(db.EmbeddedPerson).From(db.EmbeddedPerson).
+			Wheres(where.Equals(&db.EmbeddedPerson.Work.City, "d")).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		if len(persons) != 2 || persons[0].EmbeddedBase == persons[1].EmbeddedBase || persons[0].Work == persons[1].Work {
+			t.Fatalf("Expected a embedded pointer for each row, got %+v", persons)
+		}
+		if persons[0].Id != 1 || persons[1].Work.City != "h" {
+			t.Errorf("Expected the values on the embedded pointers, got %+v and %+v", persons[0], persons[1])
+		}
+		if where := driver.lastQuery().WhereOperations; len(where) != 1 || !strings.Contains(where[0].Attribute.Name, "work_city") {
+			t.Errorf("Expected where on the work city, got %+v", where)
+		}
+	})
+
+	t.Run("Anonymous", func(t *testing.T) {
+		driver.rows = [][]any{{"a"}}
+		defer func() { driver.rows = nil }()
+
+		rows, err := Select(&struct{ City *string }{&db.EmbeddedPerson.Work.City}).From(db.EmbeddedPerson).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		if len(rows) != 1 || *rows[0].City != "a" {
+			t.Errorf("Expected the city, got %+v", rows)
+		}
+	})
+}
@@ -35,6 +35,7 @@ const (
 	_ FunctionType = iota
 	UpperFunction
 	LowerFunction
+	JSONExtractFunction // value on the json path of the attribute
 )
 
 type JoinType uint
@@ -49,19 +50,32 @@ const (
 type OperatorType uint
 
 const (
-	_             OperatorType = iota
-	Equals                     // =
-	NotEquals                  // <>
-	Is                         // IS
-	IsNot                      // IS NOT
-	Greater                    // >
-	GreaterEquals              // >=
-	Less                       // <
-	LessEquals                 // <=
-	In                         // IN
-	NotIn                      // NOT IN
-	Like                       // LIKE
-	NotLike                    // NOT LIKE
-	And                        // AND
-	Or                         // OR
+	_              OperatorType = iota
+	Equals                      // =
+	NotEquals                   // <>
+	Is                          // IS
+	IsNot                       // IS NOT
+	Greater                     // >
+	GreaterEquals               // >=
+	Less                        // <
+	LessEquals                  // <=
+	In                          // IN
+	NotIn                       // NOT IN
+	Like                        // LIKE
+	NotLike                     // NOT LIKE
+	And                         // AND
+	Or                          // OR
+	JSONPathEquals              // value on the json path equals the argument
+	JSONContains                // json value contains the json argument
+	JSONHasKey                  // json value has the json path
+)
+
+type MigrateOperationType uint
+
+const (
+	_ MigrateOperationType = iota
+	CreateTableOperation
+	AddColumnOperation
+	CreateIndexOperation
+	AddForeignKeyOperation
 )
@@ -0,0 +1,65 @@
+package goe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Database errors classified by the drivers, use [errors.Is] to match
+// and [errors.As] with a [*DatabaseError] for the details.
+//
+// # Example
+//
+//	err = goe.Insert(db.User).One(&user)
+//	if errors.Is(err, goe.ErrUniqueViolation) {
+//		// return a conflict
+//	}
+var (
+	ErrUniqueViolation     = errors.New("goe: unique violation")
+	ErrForeignKeyViolation = errors.New("goe: foreign key violation")
+	ErrNotNullViolation    = errors.New("goe: not null violation")
+	ErrCheckViolation      = errors.New("goe: check violation")
+	ErrSerialization       = errors.New("goe: serialization failure")
+	ErrDeadlock            = errors.New("goe: deadlock detected")
+)
+
+// DatabaseError is a native database error classified by the driver
+// as one of the goe database errors.
+type DatabaseError struct {
+	Kind       error  // one of the goe database errors, like ErrUniqueViolation
+	Table      string // empty if the database don't report the table
+	Column     string // empty if the database don't report the column
+	Constraint string // empty if the database don't report the constraint
+	Err        error  // native error returned by the database
+}
+
+func (e *DatabaseError) Error() string {
+	switch {
+	case e.Constraint != "":
+		return fmt.Sprintf("%v on constraint %q: %v", e.Kind, e.Constraint, e.Err)
+	case e.Column != "":
+		return fmt.Sprintf("%v on column %q: %v", e.Kind, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+func (e *DatabaseError) Is(target error) bool {
+	return e.Kind == target
+}
+
+func (e *DatabaseError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError returns the error classified by the driver
+func (c DatabaseConfig) classifyError(err error) error {
+	if err == nil || c.classify == nil {
+		return err
+	}
+
+	var databaseError *DatabaseError
+	if errors.As(err, &databaseError) {
+		return err
+	}
+	return c.classify(err)
+}
@@ -0,0 +1,420 @@
+// Package gen generates goe mapped structs from a existing database schema.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/utils"
+)
+
+type Config struct {
+	Package string // package name of the generated file, default is "models"
+	// DataType returns the database type used to migrate a go type,
+	// used to find the go type of each column; see [goe.Introspector].
+	DataType func(string) string
+}
+
+// go types in order of preference, as they are named by the migrate structs
+var goTypes = []string{
+	"int", "int64", "int32", "int16", "int8",
+	"uint", "uint64", "uint32", "uint16", "uint8",
+	"float64", "float32", "string", "bool",
+	"time.Time", "[]uint8", "uuid.UUID",
+}
+
+// used when the driver don't match any go type
+var nativeTypes = map[string]string{
+	"integer":                     "int",
+	"int":                         "int",
+	"int2":                        "int16",
+	"smallint":                    "int16",
+	"int4":                        "int32",
+	"int8":                        "int64",
+	"bigint":                      "int64",
+	"serial":                      "int",
+	"bigserial":                   "int64",
+	"real":                        "float32",
+	"float4":                      "float32",
+	"float8":                      "float64",
+	"double precision":            "float64",
+	"numeric":                     "float64",
+	"decimal":                     "float64",
+	"text":                        "string",
+	"varchar":                     "string",
+	"character varying":           "string",
+	"char":                        "string",
+	"character":                   "string",
+	"bool":                        "bool",
+	"boolean":                     "bool",
+	"date":                        "time.Time",
+	"datetime":                    "time.Time",
+	"timestamp":                   "time.Time",
+	"timestamptz":                 "time.Time",
+	"timestamp without time zone": "time.Time",
+	"timestamp with time zone":    "time.Time",
+	"blob":                        "[]uint8",
+	"bytea":                       "[]uint8",
+	"uuid":                        "uuid.UUID",
+}
+
+type generator struct {
+	config  Config
+	buf     bytes.Buffer
+	imports map[string]bool
+	structs map[string]string // table name to struct name
+	slices  map[string][]string
+}
+
+// Generate writes the go structs and the Database struct for the schema,
+// the schema is usually returned by [goe.Introspector.IntrospectContext].
+//
+// # Example
+//
+//	driver := sqlite.Open("legacy.db", sqlite.Config{})
+//	err := driver.Init()
+//	...
+//	schema, err := driver.IntrospectContext(ctx)
+//	...
+//	err = gen.Generate(os.Stdout, schema, gen.Config{Package: "models", DataType: driver.DataType})
+func Generate(w io.Writer, schema *goe.Migrator, config Config) error {
+	if config.Package == "" {
+		config.Package = "models"
+	}
+	g := generator{
+		config:  config,
+		imports: make(map[string]bool),
+		structs: make(map[string]string),
+		slices:  make(map[string][]string),
+	}
+
+	tables := make([]string, 0, len(schema.Tables))
+	for name := range schema.Tables {
+		tables = append(tables, name)
+		g.structs[name] = structName(name)
+	}
+	slices.Sort(tables)
+
+	for _, name := range tables {
+		for _, mto := range schema.Tables[name].ManyToOnes {
+			if target, ok := g.structs[mto.TargetTable]; ok && !slices.Contains(g.slices[target], g.structs[name]) {
+				g.slices[target] = append(g.slices[target], g.structs[name])
+			}
+		}
+	}
+
+	body := bytes.Buffer{}
+	for _, name := range tables {
+		g.writeTable(&body, schema.Tables[name])
+	}
+
+	fmt.Fprintf(&g.buf, "// Generated by goe gen from the database schema.\n\npackage %v\n\nimport (\n", config.Package)
+	if g.imports["time"] {
+		g.buf.WriteString("\t\"time\"\n\n")
+	}
+	g.buf.WriteString("\t\"github.com/go-goe/goe\"\n")
+	if g.imports["uuid"] {
+		g.buf.WriteString("\t\"github.com/google/uuid\"\n")
+	}
+	g.buf.WriteString(")\n\n")
+	g.buf.Write(body.Bytes())
+
+	g.buf.WriteString("type Database struct {\n")
+	for _, name := range tables {
+		fmt.Fprintf(&g.buf, "\t%v *%v\n", g.structs[name], g.structs[name])
+	}
+	g.buf.WriteString("\t*goe.DB\n}\n")
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("goe: error formatting generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generatedField struct {
+	name    string
+	goType  string
+	tags    []string
+	comment string
+}
+
+func (g *generator) writeTable(w *bytes.Buffer, table *goe.TableMigrate) {
+	name := g.structs[table.Name]
+	if utils.TableNamePattern(name) != table.Name {
+		fmt.Fprintf(w, "// %v is mapped by goe to table %q, but the database table is %q\n", name, utils.TableNamePattern(name), table.Name)
+	}
+	fields := make([]generatedField, 0)
+
+	singleId := len(table.PrimaryKeys) == 1 && strings.EqualFold(table.PrimaryKeys[0].Name, "id")
+	for _, pk := range table.PrimaryKeys {
+		at := goe.AttributeMigrate{Name: pk.Name, DataType: pk.DataType}
+		if !pk.AutoIncrement {
+			// the default of a auto increment key is the sequence
+			at.Default = pk.Default
+		}
+		f := g.attributeField(table, at)
+		if !singleId {
+			f.tags = append([]string{"pk"}, f.tags...)
+		}
+		// goe migrates and inserts every integer key as auto increment
+		if !pk.AutoIncrement && isInteger(f.goType) && f.comment == "" {
+			f.comment = "goe maps as auto increment, but the database key is not auto increment"
+		}
+		fields = append(fields, f)
+	}
+
+	for _, at := range table.Attributes {
+		fields = append(fields, g.attributeField(table, at))
+	}
+	for _, mto := range table.ManyToOnes {
+		f := g.attributeField(table, mto.AttributeMigrate)
+		f.tags = append(f.tags, foreignKeyTags(g.structs[mto.TargetTable], mto.TargetColumn, mto.OnDelete, mto.OnUpdate)...)
+		fields = append(fields, f)
+	}
+	for _, oto := range table.OneToOnes {
+		f := g.attributeField(table, oto.AttributeMigrate)
+		f.tags = append(f.tags, foreignKeyTags(g.structs[oto.TargetTable], oto.TargetColumn, oto.OnDelete, oto.OnUpdate)...)
+		fields = append(fields, f)
+	}
+
+	for i := range fields {
+		if spec := indexTags(table, fields[i].column()); spec != "" {
+			fields[i].tags = append(fields[i].tags, spec)
+		}
+	}
+
+	fmt.Fprintf(w, "type %v struct {\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(w, "\t%v %v", f.name, f.goType)
+		if len(f.tags) != 0 {
+			fmt.Fprintf(w, " %v", structTag(f.tags))
+		}
+		if f.comment != "" {
+			fmt.Fprintf(w, " // %v", f.comment)
+		}
+		w.WriteString("\n")
+	}
+	for _, s := range g.slices[name] {
+		fmt.Fprintf(w, "\t%v []%v\n", plural(s), s)
+	}
+	w.WriteString("}\n\n")
+}
+
+func (g *generator) attributeField(table *goe.TableMigrate, at goe.AttributeMigrate) generatedField {
+	f := g.field(at.Name, at.DataType, at.Nullable)
+	if at.Default != "" {
+		f.tags = append(f.tags, "default:"+at.Default)
+	}
+	checkName := table.Name + "_" + at.Name + "_check"
+	if i := slices.IndexFunc(table.Checks, func(c goe.CheckMigrate) bool { return c.Name == checkName }); i != -1 {
+		f.tags = append(f.tags, "check:"+table.Checks[i].Expression)
+	}
+	return f
+}
+
+func (g *generator) field(column, dataType string, nullable bool) generatedField {
+	f := generatedField{name: fieldName(column)}
+	if utils.ColumnNamePattern(f.name) != column {
+		f.comment = fmt.Sprintf("goe maps to column %q, but the database column is %q", utils.ColumnNamePattern(f.name), column)
+	}
+
+	goType, exact := g.goType(dataType)
+	if goType == "" {
+		goType = "string"
+	}
+	if !exact {
+		f.tags = append(f.tags, "type:"+dataType)
+	}
+	switch goType {
+	case "time.Time":
+		g.imports["time"] = true
+	case "uuid.UUID":
+		g.imports["uuid"] = true
+	case "[]uint8":
+		goType = "[]byte"
+	}
+	if nullable {
+		goType = "*" + goType
+	}
+	f.goType = goType
+	return f
+}
+
+func (f generatedField) column() string {
+	return utils.ColumnNamePattern(f.name)
+}
+
+// goType returns the go type that the driver migrates as dataType,
+// exact is false if the go type needs a "type" tag to be migrated as dataType
+func (g *generator) goType(dataType string) (goType string, exact bool) {
+	if g.config.DataType != nil {
+		for _, t := range goTypes {
+			if strings.EqualFold(g.config.DataType(t), dataType) {
+				return t, true
+			}
+		}
+	}
+	native := strings.ToLower(dataType)
+	if i := strings.Index(native, "("); i != -1 {
+		native = strings.TrimSpace(native[:i])
+	}
+	goType = nativeTypes[native]
+	return goType, goType != "" && g.config.DataType == nil && !strings.Contains(dataType, "(")
+}
+
+func foreignKeyTags(target, column, onDelete, onUpdate string) []string {
+	tags := []string{"fk:" + target + "." + fieldName(column)}
+	if onDelete != "" && onDelete != "NO ACTION" {
+		tags = append(tags, "onDelete:"+strings.ToLower(onDelete))
+	}
+	if onUpdate != "" && onUpdate != "NO ACTION" {
+		tags = append(tags, "onUpdate:"+strings.ToLower(onUpdate))
+	}
+	return tags
+}
+
+// indexTags returns the "index()" tag of all indexes that uses the column
+func indexTags(table *goe.TableMigrate, column string) string {
+	specs := make([]string, 0)
+	for _, in := range table.Indexes {
+		options := make([]string, 0)
+		if i := slices.IndexFunc(in.Attributes, func(a goe.AttributeMigrate) bool { return a.Name == column }); i != -1 {
+			if in.Unique {
+				options = append(options, "unique")
+			}
+			options = append(options, "n:"+strings.TrimPrefix(in.Name, table.Name+"_"))
+			if i < len(in.Columns) && in.Columns[i].Descending {
+				options = append(options, "desc")
+			}
+		} else if slices.ContainsFunc(in.Include, func(a goe.AttributeMigrate) bool { return a.Name == column }) {
+			if in.Unique {
+				options = append(options, "unique")
+			}
+			options = append(options, "n:"+strings.TrimPrefix(in.Name, table.Name+"_"), "include")
+		} else {
+			continue
+		}
+		if in.Method != "" {
+			options = append(options, "using:"+in.Method)
+		}
+		if in.Where != "" {
+			options = append(options, "where:("+in.Where+")")
+		}
+		specs = append(specs, strings.Join(options, " "))
+	}
+	if len(specs) == 0 {
+		return ""
+	}
+	return "index(" + strings.Join(specs, ",") + ")"
+}
+
+func isInteger(goType string) bool {
+	return strings.Contains(goType, "int") && !strings.HasPrefix(goType, "[]")
+}
+
+// irregular plurals of the last word of the table
+var irregulars = map[string]string{
+	"people":   "person",
+	"children": "child",
+	"men":      "man",
+	"women":    "woman",
+	"mice":     "mouse",
+	"geese":    "goose",
+	"data":     "data",
+	"news":     "news",
+	"series":   "series",
+	"species":  "species",
+}
+
+// structName returns the struct name of the table, the last word of the
+// table is turned into singular; tables that goe don't map from the struct
+// name are marked with a comment by writeTable
+func structName(table string) string {
+	prefix, word := "", table
+	if i := strings.LastIndexAny(table, "_ -"); i != -1 {
+		prefix, word = table[:i+1], table[i+1:]
+	}
+	return fieldName(prefix + singular(word))
+}
+
+// singular returns the english singular of the word
+func singular(word string) string {
+	lower := strings.ToLower(word)
+	if s, ok := irregulars[lower]; ok {
+		return s
+	}
+	switch {
+	case len(lower) > 3 && strings.HasSuffix(lower, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "shes"), strings.HasSuffix(lower, "ches"),
+		strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zzes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		// address, status, analysis
+		return word
+	case len(lower) > 1 && strings.HasSuffix(lower, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// plural returns the english plural of the struct name, only the last word
+// of the name is changed
+func plural(name string) string {
+	lower := strings.ToLower(name)
+	for p, s := range irregulars {
+		i := len(name) - len(s)
+		if strings.HasSuffix(lower, s) && (i == 0 || unicode.IsUpper(rune(name[i]))) {
+			if unicode.IsUpper(rune(name[i])) {
+				p = string(unicode.ToUpper(rune(p[0]))) + p[1:]
+			}
+			return name[:i] + p
+		}
+	}
+	switch {
+	case len(lower) > 1 && strings.HasSuffix(lower, "y") && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// structTag returns the goe struct tag of the tags, the values are quoted as a go string
+// and the tag is written as a interpreted string if it has a backquote
+func structTag(tags []string) string {
+	tag := "goe:" + strconv.Quote(strings.Join(tags, ";"))
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// fieldName converts a snake case column to a go field name
+func fieldName(column string) string {
+	result := strings.Builder{}
+	upper := true
+	for _, r := range column {
+		if r == '_' || r == ' ' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			result.WriteRune(unicode.ToUpper(r))
+			upper = false
+			continue
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
@@ -0,0 +1,122 @@
+package gen
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-goe/goe"
+)
+
+func TestStructName(t *testing.T) {
+	testCases := []struct {
+		table string
+		want  string
+	}{
+		{table: "users", want: "User"},
+		{table: "categories", want: "Category"},
+		{table: "addresses", want: "Address"},
+		{table: "boxes", want: "Box"},
+		{table: "branches", want: "Branch"},
+		{table: "status", want: "Status"},
+		{table: "analysis", want: "Analysis"},
+		{table: "people", want: "Person"},
+		{table: "series", want: "Series"},
+		{table: "order_items", want: "OrderItem"},
+		{table: "user_addresses", want: "UserAddress"},
+		{table: "s", want: "S"},
+		{table: "info", want: "Info"},
+	}
+	for _, tC := range testCases {
+		if got := structName(tC.table); got != tC.want {
+			t.Errorf("Expected structName(%q) %q, got %q", tC.table, tC.want, got)
+		}
+	}
+}
+
+func TestGeneratePrimaryKeys(t *testing.T) {
+	schema := &goe.Migrator{Tables: map[string]*goe.TableMigrate{
+		"countries": {
+			Name:        "countries",
+			PrimaryKeys: []goe.PrimaryKeyMigrate{{Name: "id", DataType: "int"}},
+			Attributes:  []goe.AttributeMigrate{{Name: "name", DataType: "string"}},
+		},
+		"users": {
+			Name:        "users",
+			PrimaryKeys: []goe.PrimaryKeyMigrate{{Name: "id", DataType: "int", AutoIncrement: true, Default: "nextval('users_id_seq'::regclass)"}},
+		},
+		"codes": {
+			Name:        "codes",
+			PrimaryKeys: []goe.PrimaryKeyMigrate{{Name: "code", DataType: "string", Default: "'none'"}},
+			Checks:      []goe.CheckMigrate{{Name: "codes_code_check", Expression: "code <> ''"}},
+		},
+	}}
+
+	var buf bytes.Buffer
+	err := Generate(&buf, schema, Config{DataType: func(s string) string { return s }})
+	if err != nil {
+		t.Fatalf("Expected generate, got error: %v", err)
+	}
+	src := buf.String()
+
+	for _, want := range []string{
+		"Id   int // goe maps as auto increment, but the database key is not auto increment",
+		"type User struct {\n\tId int\n}",
+		"Code string `goe:\"pk;default:'none';check:code <> ''\"`",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("Expected %q on generated code, got:\n%v", want, src)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	testCases := []struct {
+		name string
+		want string
+	}{
+		{name: "User", want: "Users"},
+		{name: "Category", want: "Categories"},
+		{name: "Day", want: "Days"},
+		{name: "Address", want: "Addresses"},
+		{name: "Status", want: "Statuses"},
+		{name: "Box", want: "Boxes"},
+		{name: "Branch", want: "Branches"},
+		{name: "Person", want: "People"},
+		{name: "Woman", want: "Women"},
+		{name: "Human", want: "Humans"},
+		{name: "SalesPerson", want: "SalesPeople"},
+		{name: "Series", want: "Series"},
+		{name: "OrderItem", want: "OrderItems"},
+	}
+	for _, tC := range testCases {
+		if got := plural(tC.name); got != tC.want {
+			t.Errorf("Expected plural(%q) %q, got %q", tC.name, tC.want, got)
+		}
+	}
+}
+
+func TestStructTag(t *testing.T) {
+	testCases := []struct {
+		desc string
+		tags []string
+		want string
+	}{
+		{desc: "Semicolon", tags: []string{"default:'a;b'", "check:name <> ';'"}, want: "default:'a;b';check:name <> ';'"},
+		{desc: "Quote", tags: []string{`default:'"a"'`}, want: `default:'"a"'`},
+		{desc: "Backquote", tags: []string{"check:name <> '`'"}, want: "check:name <> '`'"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tag, err := strconv.Unquote(structTag(tC.tags))
+			if err != nil {
+				t.Fatalf("Expected a go string literal, got error: %v", err)
+			}
+			if got := reflect.StructTag(tag).Get("goe"); got != tC.want {
+				t.Errorf("Expected tag %q, got %q", tC.want, got)
+			}
+		})
+	}
+}
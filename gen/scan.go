@@ -0,0 +1,361 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Scanners writes a [goe.Scanner] for each struct mapped by the Database struct
+// declared on the package of dir, the files named as skip are not parsed.
+//
+// The generated scanners are registered on init and used by goe in place of reflection
+// when selecting the mapped structs.
+//
+// # Example
+//
+//	//go:generate go run github.com/go-goe/goe/cmd/goe scan -o goe_scan.go
+func Scanners(w io.Writer, dir string, skip ...string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	var pkg string
+	structs := make(map[string]*ast.StructType)
+	types := make(map[string]ast.Expr)
+	var tables []string
+	for _, file := range files {
+		name := filepath.Base(file)
+		if strings.HasSuffix(name, "_test.go") || slices.Contains(skip, name) {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		pkg = f.Name.Name
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				types[ts.Name.Name] = ts.Type
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				structs[ts.Name.Name] = st
+				if t := databaseTables(st); t != nil {
+					tables = t
+				}
+			}
+		}
+	}
+
+	if tables == nil {
+		return fmt.Errorf("goe: not found a Database struct with a *goe.DB as last field on %q", dir)
+	}
+
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, "// Code generated by goe scan. DO NOT EDIT.\n\npackage %v\n\nimport \"github.com/go-goe/goe\"\n\nfunc init() {\n", pkg)
+	for _, table := range tables {
+		st, ok := structs[table]
+		if !ok {
+			continue
+		}
+		fields := mappedFields(st, structs, types)
+		pointers := embeddedPointers(st, structs, types)
+		fmt.Fprintf(&buf, "\tgoe.RegisterScanner(goe.Scanner[%v]{\n\t\tFields: []string{", table)
+		for i, f := range fields {
+			if i != 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%q", f)
+		}
+		fmt.Fprintf(&buf, "},\n\t\tDest: func(v *%v) []any {\n", table)
+		for _, p := range pointers {
+			fmt.Fprintf(&buf, "\t\t\tif v.%v == nil {\n\t\t\t\tv.%v = new(%v)\n\t\t\t}\n", p.path, p.path, p.typeName)
+		}
+		buf.WriteString("\t\t\treturn []any{")
+		for i, f := range fields {
+			if i != 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "&v.%v", f)
+		}
+		buf.WriteString("}\n\t\t},\n\t})\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("goe: error formatting generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// databaseTables returns the tables of a Database struct, or nil if the struct
+// don't have a *goe.DB as last field
+func databaseTables(st *ast.StructType) []string {
+	fields := st.Fields.List
+	if len(fields) == 0 {
+		return nil
+	}
+	star, ok := fields[len(fields)-1].Type.(*ast.StarExpr)
+	if !ok {
+		return nil
+	}
+	if sel, ok := star.X.(*ast.SelectorExpr); !ok || sel.Sel.Name != "DB" {
+		return nil
+	}
+
+	tables := make([]string, 0, len(fields)-1)
+	for _, f := range fields[:len(fields)-1] {
+		if star, ok := f.Type.(*ast.StarExpr); ok {
+			if ident, ok := star.X.(*ast.Ident); ok {
+				tables = append(tables, ident.Name)
+			}
+		}
+	}
+	return tables
+}
+
+// mappedField is a field selected by goe, with the path from the table
+type mappedField struct {
+	path     string // Go path, like Address.Street
+	name     string // name with the embedded prefix, used by the promotion rules
+	depth    int
+	pointers []embeddedPointer // embedded struct pointers on the path
+}
+
+// embeddedPointer is a pointer to a embedded struct, allocated before the scan
+type embeddedPointer struct {
+	path     string
+	typeName string
+}
+
+// mappedFields returns the fields that goe selects, in the struct order
+func mappedFields(st *ast.StructType, structs map[string]*ast.StructType, types map[string]ast.Expr) []string {
+	fields := promoteFields(structFields(st, structs, types, nil))
+	paths := make([]string, len(fields))
+	for i, f := range fields {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// embeddedPointers returns the pointers to allocate for fields, in the path order
+func embeddedPointers(st *ast.StructType, structs map[string]*ast.StructType, types map[string]ast.Expr) []embeddedPointer {
+	var pointers []embeddedPointer
+	for _, f := range promoteFields(structFields(st, structs, types, nil)) {
+		for _, p := range f.pointers {
+			if !slices.Contains(pointers, p) {
+				pointers = append(pointers, p)
+			}
+		}
+	}
+	return pointers
+}
+
+// structFields flattens the fields of st like goe, the embedded structs on path are not flattened again
+func structFields(st *ast.StructType, structs map[string]*ast.StructType, types map[string]ast.Expr, path []*ast.StructType) []mappedField {
+	path = append(path, st)
+	fields := make([]mappedField, 0, len(st.Fields.List))
+	for _, f := range st.Fields.List {
+		tags := goeTags(f)
+		if embedded := embeddedStruct(f, structs); embedded != nil {
+			if slices.Contains(path, embedded) {
+				continue
+			}
+			names := []string{typeName(f.Type)}
+			if len(f.Names) != 0 {
+				names = names[:0]
+				for _, name := range f.Names {
+					names = append(names, name.Name)
+				}
+			}
+			prefix := embeddedPrefix(tags)
+			_, isPointer := f.Type.(*ast.StarExpr)
+			for _, name := range names {
+				for _, field := range structFields(embedded, structs, types, path) {
+					pointers := make([]embeddedPointer, 0, len(field.pointers)+1)
+					if isPointer {
+						pointers = append(pointers, embeddedPointer{path: name, typeName: typeName(f.Type)})
+					}
+					for _, p := range field.pointers {
+						pointers = append(pointers, embeddedPointer{path: name + "." + p.path, typeName: p.typeName})
+					}
+					field.path = name + "." + field.path
+					field.name = prefix + field.name
+					field.depth++
+					field.pointers = pointers
+					fields = append(fields, field)
+				}
+			}
+			continue
+		}
+		if !mappedType(f.Type, types, false) && !slices.Contains(tags, "json") {
+			continue
+		}
+		if ignored(f) || slices.Contains(tags, "writeonly") {
+			continue
+		}
+		if len(f.Names) == 0 {
+			fields = append(fields, mappedField{path: typeName(f.Type), name: typeName(f.Type)})
+			continue
+		}
+		for _, name := range f.Names {
+			fields = append(fields, mappedField{path: name.Name, name: name.Name})
+		}
+	}
+	return fields
+}
+
+// promoteFields removes the fields shadowed or ambiguous by name, following the Go promotion rules like goe
+func promoteFields(fields []mappedField) []mappedField {
+	depths := make(map[string][]int, len(fields))
+	for _, f := range fields {
+		depths[f.name] = append(depths[f.name], f.depth)
+	}
+	return slices.DeleteFunc(fields, func(f mappedField) bool {
+		d := depths[f.name]
+		if len(d) == 1 {
+			return false
+		}
+		minDepth := slices.Min(d)
+		count := 0
+		for _, depth := range d {
+			if depth == minDepth {
+				count++
+			}
+		}
+		return f.depth != minDepth || count != 1
+	})
+}
+
+// embeddedPrefix returns the "prefix:" tag as a field name prefix, "addr_" returns "Addr"
+func embeddedPrefix(tags []string) string {
+	var prefix strings.Builder
+	for _, tag := range tags {
+		value, ok := strings.CutPrefix(tag, "prefix:")
+		if !ok {
+			continue
+		}
+		for _, part := range strings.Split(value, "_") {
+			if part != "" {
+				prefix.WriteString(strings.ToUpper(part[:1]) + part[1:])
+			}
+		}
+	}
+	return prefix.String()
+}
+
+// embeddedStruct returns the struct of a anonymous field or a field with the embedded tag,
+// the fields of a embedded struct are mapped as columns of the table; the struct can be a pointer
+func embeddedStruct(f *ast.Field, structs map[string]*ast.StructType) *ast.StructType {
+	expr := f.Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	st, ok := structs[ident.Name]
+	if !ok {
+		return nil
+	}
+	tags := goeTags(f)
+	if ignored(f) || slices.Contains(tags, "json") {
+		return nil
+	}
+	if len(f.Names) == 0 || slices.Contains(tags, "embedded") {
+		return st
+	}
+	return nil
+}
+
+// ignored reports if f has the tag goe:"-", like goe only the exact tag is ignored
+func ignored(f *ast.Field) bool {
+	if f.Tag == nil {
+		return false
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return false
+	}
+	return reflect.StructTag(tag).Get("goe") == "-"
+}
+
+// goeTags returns the values of the goe tag of f
+func goeTags(f *ast.Field) []string {
+	if f.Tag == nil {
+		return nil
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(reflect.StructTag(tag).Get("goe"), ";")
+}
+
+// mappedType follows the goe mapping, slices are only mapped as []byte
+// and structs are only mapped as time.Time; other types need the json tag.
+// The types declared on the package are checked by the underlying type.
+func mappedType(expr ast.Expr, types map[string]ast.Expr, pointer bool) bool {
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return true
+		}
+		ident, ok := t.Elt.(*ast.Ident)
+		return ok && (ident.Name == "byte" || ident.Name == "uint8")
+	case *ast.Ident:
+		if t.Name == "any" || t.Name == "error" {
+			return false
+		}
+		underlying, ok := types[t.Name]
+		if !ok || t.Name == "Time" {
+			return true
+		}
+		delete(types, t.Name) // recursive types
+		defer func() { types[t.Name] = underlying }()
+		return mappedType(underlying, types, pointer)
+	case *ast.StarExpr:
+		return !pointer && mappedType(t.X, types, true)
+	case *ast.StructType, *ast.MapType, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return false
+	}
+	return true
+}
+
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	}
+	return ""
+}
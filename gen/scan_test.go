@@ -0,0 +1,131 @@
+package gen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const scanSource = `package models
+
+type Labels map[string]string
+
+type Tags []string
+
+type Hash []byte
+
+type Address struct {
+	Street string
+}
+
+type User struct {
+	Id        int
+	Name      *string
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	Address   *Address
+	Home      Address
+	Labels    Labels
+	Meta      map[string]any
+	Tags      Tags
+	Hash      Hash
+	Raw       []byte
+	Payload   Labels ` + "`goe:\"json\"`" + `
+	Any       any
+	Ref       **int
+	Label     string ` + "`goe:\"-\"`" + `
+	Note      string ` + "`goe:\"index;-\"`" + `
+}
+`
+
+func TestMappedFields(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "models.go", scanSource, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("Expected parse, got error: %v", err)
+	}
+	structs := make(map[string]*ast.StructType)
+	types := make(map[string]ast.Expr)
+	for _, decl := range f.Decls {
+		for _, spec := range decl.(*ast.GenDecl).Specs {
+			ts := spec.(*ast.TypeSpec)
+			types[ts.Name.Name] = ts.Type
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				structs[ts.Name.Name] = st
+			}
+		}
+	}
+
+	// only the exact tag "-" is ignored, like goe
+	want := []string{"Id", "Name", "CreatedAt", "DeletedAt", "Hash", "Raw", "Payload", "Note"}
+	if got := mappedFields(structs["User"], structs, types); !slices.Equal(got, want) {
+		t.Errorf("Expected mapped fields %v, got %v", want, got)
+	}
+}
+
+const embeddedSource = `package models
+
+import "github.com/go-goe/goe"
+
+type Base struct {
+	Id   int
+	Name string
+}
+
+type Audit struct {
+	Name    string
+	Version int
+}
+
+type Address struct {
+	Street string
+}
+
+type Node struct {
+	Id int
+	*Node
+}
+
+type Person struct {
+	*Base
+	Audit
+	Home    Address  ` + "`goe:\"embedded;prefix:home_\"`" + `
+	Work    *Address ` + "`goe:\"embedded;prefix:work_\"`" + `
+	Version int
+}
+
+type Database struct {
+	Person *Person
+	Node   *Node
+	*goe.DB
+}
+`
+
+func TestScannersEmbedded(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(embeddedSource), 0o644); err != nil {
+		t.Fatalf("Expected source file, got error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := Scanners(&b, dir); err != nil {
+		t.Fatalf("Expected scanners, got error: %v", err)
+	}
+	src := b.String()
+	for _, want := range []string{
+		// Name is ambiguous between Base and Audit, Version of Audit is shadowed by Person
+		`Fields: []string{"Base.Id", "Home.Street", "Work.Street", "Version"}`,
+		"if v.Base == nil {\n\t\t\t\tv.Base = new(Base)\n\t\t\t}",
+		"if v.Work == nil {\n\t\t\t\tv.Work = new(Address)\n\t\t\t}",
+		"return []any{&v.Base.Id, &v.Home.Street, &v.Work.Street, &v.Version}",
+		`Fields: []string{"Id"}`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("Expected %q on the generated scanners, got:\n%v", want, src)
+		}
+	}
+}
@@ -7,18 +7,39 @@ import (
 	"reflect"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
-func init() {
-	addrMap = &goeMap{mapField: make(map[uintptr]field)}
-}
-
 // Open opens a database connection
 //
 // # Example
 //
 //	goe.Open[Database](postgres.Open("user=postgres password=postgres host=localhost port=5432 database=postgres", postgres.Config{}))
 func Open[T any](driver Driver) (*T, error) {
+	return OpenReplicas[T](driver)
+}
+
+// Replica is a read replica used by [OpenReplicas].
+type Replica struct {
+	Driver Driver
+	Weight int // relative share of the selects, values less than 1 are used as 1
+}
+
+// OpenReplicas opens a database connection with a primary and read replicas.
+//
+// The selects (Select, Find and List) are distributed between the replicas by weight,
+// writes, raw queries and anything inside a transaction runs on the primary.
+// Use OnPrimary on a select to read your own writes.
+//
+// # Example
+//
+//	goe.OpenReplicas[Database](
+//		postgres.Open("host=primary ...", postgres.Config{}),
+//		goe.Replica{Driver: postgres.Open("host=replica1 ...", postgres.Config{}), Weight: 2},
+//		goe.Replica{Driver: postgres.Open("host=replica2 ...", postgres.Config{}), Weight: 1},
+//	)
+func OpenReplicas[T any](driver Driver, replicas ...Replica) (*T, error) {
 	db := new(T)
 	valueOf := reflect.ValueOf(db).Elem()
 	if valueOf.Kind() != reflect.Struct {
@@ -30,7 +51,7 @@ func Open[T any](driver Driver) (*T, error) {
 		return nil, errors.New("goe: invalid database, last struct field needs to be goe.DB")
 	}
 
-	dbTarget := new(DB)
+	dbTarget := &DB{fields: make(map[uintptr]field)}
 	valueOf.Field(dbId).Set(reflect.ValueOf(dbTarget))
 
 	// set value for Fields
@@ -38,8 +59,12 @@ func Open[T any](driver Driver) (*T, error) {
 		if valueOf.Field(i).IsNil() {
 			valueOf.Field(i).Set(reflect.ValueOf(reflect.New(valueOf.Field(i).Type().Elem()).Interface()))
 		}
+		dbTarget.tables = append(dbTarget.tables, newTableRange(valueOf.Field(i), dbTarget))
+		allocEmbedded(dbTarget, valueOf.Field(i).Elem())
 	}
 
+	driver.GetDatabaseConfig().initCodecs()
+
 	var err error
 	// init Fields
 	for tableId := range dbId {
@@ -49,16 +74,72 @@ func Open[T any](driver Driver) (*T, error) {
 		}
 	}
 
-	driver.GetDatabaseConfig().databaseName = driver.Name()
+	if err = checkCapabilities(driver, driver.GetDatabaseConfig()); err != nil {
+		return nil, err
+	}
+	for _, r := range replicas {
+		if r.Driver == nil {
+			return nil, errors.New("goe: invalid replica, the driver can't be nil")
+		}
+		// the replicas run the queries with the primary config
+		if err = checkCapabilities(r.Driver, driver.GetDatabaseConfig()); err != nil {
+			return nil, err
+		}
+	}
+
+	initConfig(driver)
 	err = driver.Init()
 	if err != nil {
 		return nil, driver.GetDatabaseConfig().ErrorHandler(context.TODO(), err)
 	}
 
+	for _, r := range replicas {
+		initConfig(r.Driver)
+		err = r.Driver.Init()
+		if err != nil {
+			err = r.Driver.GetDatabaseConfig().ErrorHandler(context.TODO(), err)
+			// close the drivers opened before the replica
+			driver.Close()
+			for _, replica := range dbTarget.replicas {
+				replica.Close()
+			}
+			return nil, err
+		}
+		dbTarget.replicas = append(dbTarget.replicas, r.Driver)
+		dbTarget.weights = append(dbTarget.weights, max(r.Weight, 1)+dbTarget.totalWeight())
+	}
+
 	dbTarget.driver = driver
+	addrMap.register(dbTarget)
 	return db, nil
 }
 
+// initConfig sets on the driver config the capabilities of the driver
+func initConfig(driver Driver) {
+	config := driver.GetDatabaseConfig()
+	config.databaseName = driver.Name()
+	config.generations = new(tableGenerations)
+	config.explains = make(chan struct{}, maxExplains)
+	if explainer, ok := driver.(Explainer); ok {
+		config.explain = explainer.ExplainContext
+	}
+	if classifier, ok := driver.(ErrorClassifier); ok {
+		config.classify = classifier.ClassifyError
+	}
+}
+
+// checkCapabilities returns a error if config enables a optional capability that the driver don't implement,
+// the statement cache is created by each driver from the own config
+func checkCapabilities(driver Driver, config *DatabaseConfig) error {
+	if tagger, ok := driver.(QueryTagger); config.QueryTags && (!ok || !tagger.SupportsQueryTags()) {
+		return unsupported(driver, "QueryTags")
+	}
+	if _, ok := driver.(StatementCacher); driver.GetDatabaseConfig().StatementCacheSize > 0 && !ok {
+		return unsupported(driver, "StatementCacheSize")
+	}
+	return nil
+}
+
 // data used for map
 type infosMap struct {
 	db      *DB
@@ -87,7 +168,7 @@ type body struct {
 	fieldTypeOf reflect.Type
 	mapp        *infosMap     // used on map
 	migrate     *infosMigrate // used on migrate
-	fieldId     int
+	field       reflect.StructField
 	driver      Driver
 	nullable    bool
 	stringInfos
@@ -104,87 +185,297 @@ func skipPrimaryKey[T comparable](slice []T, value T, tables reflect.Value, fiel
 }
 
 func initField(tables reflect.Value, valueOf reflect.Value, db *DB, tableId int, driver Driver) error {
-	pks, fieldIds, err := getPk(db, valueOf.Type(), tableId, driver)
+	pks, fieldNames, err := getPk(db, valueOf.Type(), tableId, driver)
 	if err != nil {
 		return err
 	}
 
+	fields := structFields(valueOf.Type())
 	for i := range pks {
-		addrMap.set(uintptr(valueOf.Field(fieldIds[i]).Addr().UnsafePointer()), pks[i])
+		db.fields[uintptr(valueOf.FieldByIndex(pks[i].fieldId).Addr().UnsafePointer())] = pks[i]
 	}
-	var field reflect.StructField
 
-	for fieldId := range valueOf.NumField() {
-		field = valueOf.Type().Field(fieldId)
-		if skipPrimaryKey(fieldIds, fieldId, tables, field) {
+	for _, field := range fields {
+		if skipPrimaryKey(fieldNames, field.Name, tables, field) {
 			continue
 		}
-		switch valueOf.Field(fieldId).Kind() {
+		fieldOf := valueOf.FieldByIndex(field.Index)
+		mapp := &infosMap{
+			pks:     pks,
+			db:      db,
+			tableId: tableId,
+			addr:    uintptr(fieldOf.Addr().UnsafePointer()),
+		}
+		if hasCodec(driver, field, fieldOf) {
+			err = helperAttribute(body{
+				field:   field,
+				driver:  driver,
+				tables:  tables,
+				valueOf: valueOf,
+				typeOf:  valueOf.Type(),
+				mapp:    mapp,
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		switch fieldOf.Kind() {
 		case reflect.Slice:
 			err = handlerSlice(body{
-				fieldTypeOf: valueOf.Field(fieldId).Type().Elem(),
+				fieldTypeOf: fieldOf.Type().Elem(),
 				valueOf:     valueOf,
 				typeOf:      valueOf.Type(),
 				tables:      tables,
-				fieldId:     fieldId,
-				mapp: &infosMap{
-					pks:     pks,
-					db:      db,
-					tableId: tableId,
-					addr:    uintptr(valueOf.Field(fieldId).Addr().UnsafePointer()),
-				},
-				driver: driver,
+				field:       field,
+				mapp:        mapp,
+				driver:      driver,
 			}, helperAttribute)
 			if err != nil {
 				return err
 			}
 		case reflect.Struct:
-			handlerStruct(body{
-				fieldId:     fieldId,
+			err = handlerStruct(body{
+				field:       field,
 				driver:      driver,
-				fieldTypeOf: valueOf.Field(fieldId).Type(),
+				fieldTypeOf: fieldOf.Type(),
 				valueOf:     valueOf,
-				mapp: &infosMap{
-					pks:     pks,
-					db:      db,
-					tableId: tableId,
-					addr:    uintptr(valueOf.Field(fieldId).Addr().UnsafePointer()),
-				},
-			}, newAttr)
+				typeOf:      valueOf.Type(),
+				mapp:        mapp,
+			}, newAttribute)
+			if err != nil {
+				return err
+			}
+		case reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+			// only mapped by a codec or the json tag
+			continue
 		case reflect.Ptr:
-			helperAttribute(body{
-				fieldId:  fieldId,
+			if !mappedPointer(driver, field) {
+				continue
+			}
+			err = helperAttribute(body{
+				field:    field,
 				driver:   driver,
 				nullable: true,
 				tables:   tables,
 				valueOf:  valueOf,
 				typeOf:   valueOf.Type(),
-				mapp: &infosMap{
-					pks:     pks,
-					db:      db,
-					tableId: tableId,
-					addr:    uintptr(valueOf.Field(fieldId).Addr().UnsafePointer()),
-				},
+				mapp:     mapp,
 			})
+			if err != nil {
+				return err
+			}
 		default:
-			helperAttribute(body{
-				fieldId: fieldId,
+			err = helperAttribute(body{
+				field:   field,
 				driver:  driver,
 				tables:  tables,
 				valueOf: valueOf,
 				typeOf:  valueOf.Type(),
-				mapp: &infosMap{
-					pks:     pks,
-					db:      db,
-					tableId: tableId,
-					addr:    uintptr(valueOf.Field(fieldId).Addr().UnsafePointer()),
-				},
+				mapp:    mapp,
 			})
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// hasCodec reports if the non-pointer field is mapped by a codec or as json,
+// the pointers are mapped as nullable attributes
+func hasCodec(driver Driver, field reflect.StructField, fieldOf reflect.Value) bool {
+	if fieldOf.Kind() == reflect.Pointer {
+		return false
+	}
+	return isJSON(field) || driver.GetDatabaseConfig().getCodec(fieldOf.Type()) != nil
+}
+
+// mappedPointer reports if the pointer field is mapped as a nullable attribute,
+// it follows the same rules of the non-pointer fields
+func mappedPointer(driver Driver, field reflect.StructField) bool {
+	elem := field.Type.Elem()
+	if isJSON(field) || driver.GetDatabaseConfig().getCodec(elem) != nil {
+		return true
+	}
+	switch elem.Kind() {
+	case reflect.Struct:
+		return elem.Name() == "Time"
+	case reflect.Slice:
+		return elem.Elem().Kind() == reflect.Uint8
+	case reflect.Map, reflect.Pointer, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	}
+	return true
+}
+
+var fieldsCache sync.Map // reflect.Type => []reflect.StructField
+
+// structFields returns the fields of a mapped struct, the fields of the embedded structs
+// are flattened in place of the struct with the full path on Index.
+// The fields with the tag "-" are ignored.
+//
+// The fields of a struct tagged with "prefix:" are named with the prefix,
+// so a prefix "addr_" maps the field Street as the column addr_street.
+//
+// The names follow the Go promotion rules, a field of a embedded struct is shadowed by a
+// field with the same name on a lower depth and two fields on the same depth are both ignored.
+// The fields are cached by type and must not be changed.
+func structFields(typeOf reflect.Type) []reflect.StructField {
+	if fields, ok := fieldsCache.Load(typeOf); ok {
+		return fields.([]reflect.StructField)
+	}
+	fields := promoteFields(flattenFields(typeOf, nil))
+	fieldsCache.Store(typeOf, fields)
+	return fields
+}
+
+// flattenFields returns the fields of typeOf and of the embedded structs,
+// the embedded structs on the path are not flattened again
+func flattenFields(typeOf reflect.Type, path []reflect.Type) []reflect.StructField {
+	path = append(path, typeOf)
+	fields := make([]reflect.StructField, 0, typeOf.NumField())
+	for i := range typeOf.NumField() {
+		field := typeOf.Field(i)
+		if field.Tag.Get("goe") == "-" {
+			continue
+		}
+		if !isEmbedded(field) {
+			fields = append(fields, field)
+			continue
+		}
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if slices.Contains(path, embedded) {
+			continue
+		}
+		prefix := embeddedPrefix(field)
+		for _, f := range flattenFields(embedded, path) {
+			f.Index = append([]int{i}, f.Index...)
+			f.Name = prefix + f.Name
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// promoteFields removes the fields shadowed or ambiguous by name, keeping the order
+func promoteFields(fields []reflect.StructField) []reflect.StructField {
+	depths := make(map[string][]int, len(fields))
+	for _, f := range fields {
+		depths[f.Name] = append(depths[f.Name], len(f.Index))
+	}
+	return slices.DeleteFunc(fields, func(f reflect.StructField) bool {
+		d := depths[f.Name]
+		if len(d) == 1 {
+			return false
+		}
+		minDepth := slices.Min(d)
+		count := 0
+		for _, depth := range d {
+			if depth == minDepth {
+				count++
+			}
+		}
+		return len(f.Index) != minDepth || count != 1
+	})
+}
+
+// isEmbedded reports if the fields of the struct are mapped as columns of the table,
+// for anonymous structs or structs with the embedded tag; the struct can be a pointer
+func isEmbedded(field reflect.StructField) bool {
+	typeOf := field.Type
+	if typeOf.Kind() == reflect.Pointer {
+		typeOf = typeOf.Elem()
+	}
+	if typeOf.Kind() != reflect.Struct || typeOf == reflect.TypeFor[time.Time]() || isJSON(field) {
+		return false
+	}
+	return field.Anonymous || tagValueExist(field.Tag.Get("goe"), "embedded")
+}
+
+// fieldByIndex returns the nested field of v by index,
+// allocating the nil pointers of the embedded structs
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldValue returns the nested field of v by index,
+// or the zero value if a pointer of a embedded struct is nil
+func fieldValue(v reflect.Value, index []int) reflect.Value {
+	f, err := v.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Zero(v.Type().FieldByIndex(index).Type)
+	}
+	return f
+}
+
+// embeddedPointer reports if the path of index has a pointer to a embedded struct
+func embeddedPointer(typeOf reflect.Type, index []int) bool {
+	for _, x := range index[:len(index)-1] {
+		typeOf = typeOf.Field(x).Type
+		if typeOf.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
+
+// allocEmbedded allocates the pointers of the embedded structs of a table,
+// the memory of the embedded structs is registered as part of the table
+func allocEmbedded(db *DB, table reflect.Value) {
+	for _, field := range structFields(table.Type()) {
+		v := table
+		for _, x := range field.Index[:len(field.Index)-1] {
+			v = v.Field(x)
+			if v.Kind() != reflect.Pointer {
+				continue
+			}
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+				db.tables = append(db.tables, newTableRange(v, db))
+			}
+			v = v.Elem()
+		}
+	}
+}
+
+// embeddedPrefix returns the "prefix:" tag as a field name prefix, "addr_" returns "Addr"
+func embeddedPrefix(field reflect.StructField) string {
+	var prefix strings.Builder
+	for _, part := range strings.Split(getTagValue(field.Tag.Get("goe"), "prefix:"), "_") {
+		if part != "" {
+			prefix.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return prefix.String()
+}
+
+// fieldPath returns the Go selector of the field on index, like "BaseModel.ID"
+func fieldPath(typeOf reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, id := range index {
+		if typeOf.Kind() == reflect.Pointer {
+			typeOf = typeOf.Elem()
+		}
+		field := typeOf.Field(id)
+		names[i] = field.Name
+		typeOf = field.Type
+	}
+	return strings.Join(names, ".")
+}
+
 func handlerStruct(b body, create func(b body) error) error {
 	switch b.fieldTypeOf.Name() {
 	case "Time":
@@ -204,29 +495,33 @@ func handlerSlice(b body, helper func(b body) error) error {
 func newAttr(b body) error {
 	at := createAtt(
 		b.mapp.db,
-		b.valueOf.Type().Field(b.fieldId).Name,
+		b.field.Name,
 		b.mapp.pks[0].tableName,
 		b.mapp.tableId,
-		b.fieldId,
+		b.field.Index,
 		b.driver,
 	)
-	addrMap.set(b.mapp.addr, at)
+	at.sensitive = isSensitive(b.field)
+	at.mode = getFieldMode(b.field)
+	at.codec = jsonFieldCodec(b.field)
+	at.json = isJSON(b.field)
+	b.mapp.db.fields[b.mapp.addr] = at
 	return nil
 }
 
-func getPk(db *DB, typeOf reflect.Type, tableId int, driver Driver) ([]*pk, []int, error) {
+func getPk(db *DB, typeOf reflect.Type, tableId int, driver Driver) ([]*pk, []string, error) {
 	var pks []*pk
-	var fieldIds []int
-	var fieldId int
+	var fieldNames []string
 
 	id, valid := getId(typeOf)
 	if valid {
 		pks := make([]*pk, 1)
-		fieldIds = make([]int, 1)
-		fieldId = getFieldId(typeOf, id.Name)
-		pks[0] = createPk(db, typeOf.Name(), id.Name, isAutoIncrement(id), tableId, fieldId, driver)
-		fieldIds[0] = fieldId
-		return pks, fieldIds, nil
+		fieldNames = make([]string, 1)
+		pks[0] = createPk(db, typeOf.Name(), id.Name, isAutoIncrement(id), tableId, id.Index, driver)
+		pks[0].sensitive = isSensitive(id)
+		pks[0].mode = getFieldMode(id)
+		fieldNames[0] = id.Name
+		return pks, fieldNames, nil
 	}
 
 	fields := fieldsByTags("pk", typeOf)
@@ -235,23 +530,15 @@ func getPk(db *DB, typeOf reflect.Type, tableId int, driver Driver) ([]*pk, []in
 	}
 
 	pks = make([]*pk, len(fields))
-	fieldIds = make([]int, len(fields))
+	fieldNames = make([]string, len(fields))
 	for i := range fields {
-		fieldId = getFieldId(typeOf, fields[i].Name)
-		pks[i] = createPk(db, typeOf.Name(), fields[i].Name, isAutoIncrement(fields[i]), tableId, fieldId, driver)
-		fieldIds[i] = fieldId
+		pks[i] = createPk(db, typeOf.Name(), fields[i].Name, isAutoIncrement(fields[i]), tableId, fields[i].Index, driver)
+		pks[i].sensitive = isSensitive(fields[i])
+		pks[i].mode = getFieldMode(fields[i])
+		fieldNames[i] = fields[i].Name
 	}
 
-	return pks, fieldIds, nil
-}
-
-func getFieldId(typeOf reflect.Type, fieldName string) int {
-	for i := 0; i < typeOf.NumField(); i++ {
-		if typeOf.Field(i).Name == fieldName {
-			return i
-		}
-	}
-	return 0
+	return pks, fieldNames, nil
 }
 
 func isAutoIncrement(id reflect.StructField) bool {
@@ -301,16 +588,58 @@ func primaryKeys(str reflect.Type) (pks []reflect.StructField) {
 func fieldsByTags(tag string, str reflect.Type) (f []reflect.StructField) {
 	f = make([]reflect.StructField, 0)
 
-	for i := 0; i < str.NumField(); i++ {
-		if strings.Contains(str.Field(i).Tag.Get("goe"), tag) {
-			f = append(f, str.Field(i))
+	for _, field := range structFields(str) {
+		if tagValueExist(field.Tag.Get("goe"), tag) {
+			f = append(f, field)
 		}
 	}
 	return f
 }
 
+// fieldMode is how a mapped field is used by the queries,
+// set by the tags "readonly", "insertonly" and "writeonly"
+type fieldMode uint8
+
+const (
+	modeReadWrite  fieldMode = iota
+	modeReadOnly             // selected, never inserted or updated; like generated columns
+	modeInsertOnly           // selected and inserted, never updated
+	modeWriteOnly            // inserted and updated, never selected
+)
+
+func getFieldMode(field reflect.StructField) fieldMode {
+	tag := field.Tag.Get("goe")
+	switch {
+	case tagValueExist(tag, "readonly"):
+		return modeReadOnly
+	case tagValueExist(tag, "insertonly"):
+		return modeInsertOnly
+	case tagValueExist(tag, "writeonly"):
+		return modeWriteOnly
+	}
+	return modeReadWrite
+}
+
+func (m fieldMode) canSelect() bool {
+	return m != modeWriteOnly
+}
+
+func (m fieldMode) canInsert() bool {
+	return m != modeReadOnly
+}
+
+func (m fieldMode) canUpdate() bool {
+	return m == modeReadWrite || m == modeWriteOnly
+}
+
+// isSensitive reports if the field have the sensitive tag, the arguments
+// of sensitive fields are redacted on logs
+func isSensitive(field reflect.StructField) bool {
+	return tagValueExist(field.Tag.Get("goe"), "sensitive")
+}
+
 func getTagValue(FieldTag string, subTag string) string {
-	values := strings.Split(FieldTag, ";")
+	values := splitTag(FieldTag, ';')
 	for _, v := range values {
 		if after, found := strings.CutPrefix(v, subTag); found {
 			return after
@@ -320,6 +649,9 @@ func getTagValue(FieldTag string, subTag string) string {
 }
 
 func checkTablePattern(tables reflect.Value, field reflect.StructField) (table, prefix string) {
+	if fk := getTagValue(field.Tag.Get("goe"), "fk:"); fk != "" {
+		return foreignKeyTag(tables, fk)
+	}
 	table, prefix = prefixNamePattern(tables, field)
 	if table != "" {
 		return table, prefix
@@ -327,6 +659,48 @@ func checkTablePattern(tables reflect.Value, field reflect.StructField) (table,
 	return posfixNamePattern(tables, field)
 }
 
+// foreignKeyTag returns the target table and column from a "fk:Table.Column" tag,
+// if the column is omitted uses the primary key of the target table
+func foreignKeyTag(tables reflect.Value, fk string) (table, prefix string) {
+	table, prefix, _ = strings.Cut(fk, ".")
+	target := tables.FieldByName(table)
+	if !target.IsValid() {
+		return "", ""
+	}
+	if prefix == "" {
+		if pks := primaryKeys(target.Type().Elem()); len(pks) == 1 {
+			prefix = pks[0].Name
+		}
+	}
+	return table, prefix
+}
+
+// checkForeignKeyTag returns a error if the field has a fk tag that don't match any table
+func checkForeignKeyTag(tables reflect.Value, typeOf reflect.Type, field reflect.StructField) error {
+	fk := getTagValue(field.Tag.Get("goe"), "fk:")
+	if fk == "" {
+		return nil
+	}
+	table, prefix := foreignKeyTag(tables, fk)
+	if table == "" || prefix == "" {
+		return fmt.Errorf("goe: struct %q have a invalid fk tag %q on field %q", typeOf.Name(), fk, field.Name)
+	}
+	pks := primaryKeys(tables.FieldByName(table).Type().Elem())
+	if !slices.ContainsFunc(pks, func(pk reflect.StructField) bool { return pk.Name == prefix }) {
+		return fmt.Errorf("goe: struct %q have a fk tag %q on field %q that don't target a primary key", typeOf.Name(), fk, field.Name)
+	}
+	return nil
+}
+
+// checkForeignKeyAction returns a error if a field that is not a foreign key has a onDelete or onUpdate tag
+func checkForeignKeyAction(typeOf reflect.Type, field reflect.StructField) error {
+	tag := field.Tag.Get("goe")
+	if getTagValue(tag, "onDelete:") != "" || getTagValue(tag, "onUpdate:") != "" {
+		return fmt.Errorf("goe: struct %q have a onDelete or onUpdate tag on field %q that is not a foreign key", typeOf.Name(), field.Name)
+	}
+	return nil
+}
+
 func prefixNamePattern(tables reflect.Value, field reflect.StructField) (table, prefix string) {
 	for r := len(field.Name) - 1; r > 1; r-- {
 		if field.Name[r] < 'a' {
@@ -354,43 +728,55 @@ func posfixNamePattern(tables reflect.Value, field reflect.StructField) (table,
 }
 
 func helperAttribute(b body) error {
-	table, prefix := checkTablePattern(b.tables, b.valueOf.Type().Field(b.fieldId))
+	if err := checkForeignKeyTag(b.tables, b.typeOf, b.field); err != nil {
+		return err
+	}
+	table, prefix := checkTablePattern(b.tables, b.field)
 	if table != "" {
-		b.stringInfos = stringInfos{prefixName: prefix, tableName: table, fieldName: b.valueOf.Type().Field(b.fieldId).Name}
+		b.stringInfos = stringInfos{prefixName: prefix, tableName: table, fieldName: b.field.Name}
 		if mto := isManyToOne(b, createManyToOne, createOneToOne); mto != nil {
 			switch v := mto.(type) {
 			case *manyToOne:
 				if v == nil {
-					newAttr(b)
-					return nil
+					return newAttribute(b)
 				}
-				if addrMap.get(b.mapp.addr) == nil {
-					addrMap.set(b.mapp.addr, v)
+				if b.mapp.db.fields[b.mapp.addr] == nil {
+					b.mapp.db.fields[b.mapp.addr] = v
 					return nil
 				}
 				for _, pk := range b.mapp.pks {
-					if !b.nullable && pk.fieldId == v.fieldId {
+					if !b.nullable && slices.Equal(pk.fieldId, v.fieldId) {
 						pk.autoIncrement = false
 					}
 				}
 			case *oneToOne:
 				if v == nil {
-					newAttr(b)
-					return nil
+					return newAttribute(b)
 				}
-				if addrMap.get(b.mapp.addr) == nil {
-					addrMap.set(b.mapp.addr, v)
+				if b.mapp.db.fields[b.mapp.addr] == nil {
+					b.mapp.db.fields[b.mapp.addr] = v
 				}
 			}
 			return nil
 		}
 	}
+	return newAttribute(b)
+}
+
+// newAttribute maps the field as a attribute, that can't have referential actions
+func newAttribute(b body) error {
+	if err := checkForeignKeyAction(b.typeOf, b.field); err != nil {
+		return err
+	}
 	newAttr(b)
 	return nil
 }
 
 func getId(typeOf reflect.Type) (reflect.StructField, bool) {
-	return typeOf.FieldByNameFunc(func(s string) bool {
-		return strings.ToUpper(s) == "ID"
-	})
+	for _, field := range structFields(typeOf) {
+		if strings.ToUpper(field.Name) == "ID" {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
 }
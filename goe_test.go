@@ -0,0 +1,119 @@
+package goe
+
+import (
+	"strings"
+	"testing"
+)
+
+type FkHabitat struct {
+	Id   int
+	Name string
+}
+
+type FkAnimal struct {
+	Id          int
+	HabitatName string `goe:"fk:FkHabitat.Name"`
+}
+
+type FkAnimalDatabase struct {
+	FkHabitat *FkHabitat
+	FkAnimal  *FkAnimal
+	*DB
+}
+
+type FkNote struct {
+	Id   int
+	Text string `goe:"onDelete:cascade"`
+}
+
+type FkNoteDatabase struct {
+	FkNote *FkNote
+	*DB
+}
+
+type FkMissing struct {
+	Id        int
+	HabitatId int `goe:"fk:Habitat"`
+}
+
+type FkMissingDatabase struct {
+	FkMissing *FkMissing
+	*DB
+}
+
+type FkKeeper struct {
+	Id      int
+	HomeId  int `goe:"fk:FkHabitat;onDelete:cascade;onUpdate:set null"`
+	Name    string
+	OtherId *int `goe:"fk:FkHabitat.Id"`
+}
+
+type FkKeeperDatabase struct {
+	FkHabitat *FkHabitat
+	FkKeeper  *FkKeeper
+	*DB
+}
+
+func TestForeignKeyTag(t *testing.T) {
+	testCases := []struct {
+		desc string
+		open func() error
+		err  string
+	}{
+		{
+			desc: "NotPrimaryKey",
+			open: func() error {
+				_, err := Open[FkAnimalDatabase](newFakeDriver("SQLite"))
+				return err
+			},
+			err: `fk tag "FkHabitat.Name" on field "HabitatName" that don't target a primary key`,
+		},
+		{
+			desc: "ActionWithoutForeignKey",
+			open: func() error {
+				_, err := Open[FkNoteDatabase](newFakeDriver("SQLite"))
+				return err
+			},
+			err: `onDelete or onUpdate tag on field "Text" that is not a foreign key`,
+		},
+		{
+			desc: "MissingTable",
+			open: func() error {
+				_, err := Open[FkMissingDatabase](newFakeDriver("SQLite"))
+				return err
+			},
+			err: `invalid fk tag "Habitat" on field "HabitatId"`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tC.open()
+			if err == nil || !strings.Contains(err.Error(), tC.err) {
+				t.Errorf("Expected error %q, got: %v", tC.err, err)
+			}
+		})
+	}
+
+	db, err := Open[FkKeeperDatabase](newFakeDriver("SQLite"))
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	m := migrateFrom(db, db.driver)
+	if m.Error != nil {
+		t.Fatalf("Expected migrator, got error: %v", m.Error)
+	}
+	keeper := m.Tables["fk_keepers"]
+	if len(keeper.OneToOnes) != 2 {
+		t.Fatalf("Expected two foreign keys, got: %+v", keeper.OneToOnes)
+	}
+	home := keeper.OneToOnes[0]
+	if home.Name != "home_id" || home.TargetTable != "fk_habitats" || home.TargetColumn != "id" ||
+		home.OnDelete != "CASCADE" || home.OnUpdate != "SET NULL" {
+		t.Errorf("Expected foreign key home_id with actions, got: %+v", home)
+	}
+	if other := keeper.OneToOnes[1]; other.Name != "other_id" || !other.Nullable || other.OnDelete != "" {
+		t.Errorf("Expected nullable foreign key other_id without actions, got: %+v", other)
+	}
+}
@@ -4,56 +4,63 @@ import (
 	"context"
 	"iter"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/go-goe/goe/model"
 )
 
 func handlerValues(ctx context.Context, conn Connection, query model.Query, dbConfig *DatabaseConfig) error {
-	query.Header.Err = wrapperExec(ctx, conn, &query)
+	query.Header.Err = wrapperExec(ctx, conn, &query, dbConfig)
 	if query.Header.Err != nil {
 		return dbConfig.ErrorQueryHandler(ctx, query)
 	}
+	dbConfig.invalidateCache(conn, query)
 	dbConfig.InfoHandler(ctx, query)
 	return nil
 }
 
-func handlerValuesReturning(ctx context.Context, conn Connection, query model.Query, value reflect.Value, pkFieldId int, dbConfig *DatabaseConfig) error {
-	row := wrapperQueryRow(ctx, conn, &query)
+func handlerValuesReturning(ctx context.Context, conn Connection, query model.Query, value reflect.Value, pkFieldId []int, dbConfig *DatabaseConfig) error {
+	row := wrapperQueryRow(ctx, conn, &query, dbConfig)
 
-	query.Header.Err = row.Scan(value.Field(pkFieldId).Addr().Interface())
+	query.Header.Err = row.Scan(dbConfig.codecDest([]any{fieldByIndex(value, pkFieldId).Addr().Interface()}, nil)...)
 	if query.Header.Err != nil {
 		return dbConfig.ErrorQueryHandler(ctx, query)
 	}
+	dbConfig.invalidateCache(conn, query)
 	dbConfig.InfoHandler(ctx, query)
 	return nil
 }
 
-func handlerValuesReturningBatch(ctx context.Context, conn Connection, query model.Query, value reflect.Value, pkFieldId int, dbConfig *DatabaseConfig) error {
+func handlerValuesReturningBatch(ctx context.Context, conn Connection, query model.Query, value reflect.Value, pkFieldId []int, dbConfig *DatabaseConfig) error {
 	var rows Rows
-	rows, query.Header.Err = wrapperQuery(ctx, conn, &query)
+	rows, query.Header.Err = wrapperQuery(ctx, conn, &query, dbConfig)
 
 	if query.Header.Err != nil {
 		return dbConfig.ErrorQueryHandler(ctx, query)
 	}
 	defer rows.Close()
+	dbConfig.invalidateCache(conn, query)
 	dbConfig.InfoHandler(ctx, query)
 
 	i := 0
 	for rows.Next() {
-		query.Header.Err = rows.Scan(value.Index(i).Field(pkFieldId).Addr().Interface())
+		query.Header.Err = rows.Scan(dbConfig.codecDest([]any{fieldByIndex(value.Index(i), pkFieldId).Addr().Interface()}, nil)...)
 		if query.Header.Err != nil {
 			//TODO: add infos about row
 			return dbConfig.ErrorQueryHandler(ctx, query)
 		}
 		i++
 	}
+	if query.Header.Err = rowsErr(rows); query.Header.Err != nil {
+		return dbConfig.ErrorQueryHandler(ctx, query)
+	}
 	return nil
 }
 
-func handlerResult[T any](ctx context.Context, conn Connection, query model.Query, numFields int, anonymous bool, dbConfig *DatabaseConfig) iter.Seq2[T, error] {
+func handlerResult[T any](ctx context.Context, conn Connection, query model.Query, fields []fieldSelect, anonymous bool, scanner *Scanner[T], dbConfig *DatabaseConfig) iter.Seq2[T, error] {
 	var rows Rows
-	rows, query.Header.Err = wrapperQuery(ctx, conn, &query)
+	rows, query.Header.Err = wrapperQuery(ctx, conn, &query, dbConfig)
 
 	var v T
 	if query.Header.Err != nil {
@@ -63,35 +70,43 @@ func handlerResult[T any](ctx context.Context, conn Connection, query model.Quer
 	}
 	dbConfig.InfoHandler(ctx, query)
 
+	if scanner != nil {
+		return scanStructQuery(ctx, rows, scanner, fields, dbConfig, query)
+	}
+
 	value := reflect.TypeOf(v)
-	dest := make([]any, numFields)
+	dest := make([]any, len(fields))
 	if anonymous {
 		fieldElem := make(map[int]bool)
 		for i := range dest {
 			if value.Field(i).Type.Elem().Kind() == reflect.Pointer {
 				fieldElem[i] = true
-				dest[i] = reflect.New(value.Field(i).Type.Elem()).Interface()
+				dest[i] = dbConfig.scanDest(fields[i], value.Field(i).Type.Elem())
 				continue
 			}
-			dest[i] = reflect.New(value.Field(i).Type).Interface()
+			dest[i] = dbConfig.scanDest(fields[i], value.Field(i).Type)
 		}
 		return mapAnonymousStructQuery[T](ctx, rows, dest, value, fieldElem, dbConfig, query)
 	}
 
+	indexes := make([][]int, len(fields))
 	for i := range dest {
-		dest[i] = reflect.New(value.Field(i).Type).Interface()
+		indexes[i] = fields[i].(field).getFieldId()
+		dest[i] = dbConfig.scanDest(fields[i], value.FieldByIndex(indexes[i]).Type)
 	}
 
-	return mapStructQuery[T](ctx, rows, dest, value, dbConfig, query)
+	return mapStructQuery[T](ctx, rows, dest, value, indexes, dbConfig, query)
 }
 
-func mapStructQuery[T any](ctx context.Context, rows Rows, dest []any, value reflect.Type, dbConfig *DatabaseConfig, query model.Query) iter.Seq2[T, error] {
+func mapStructQuery[T any](ctx context.Context, rows Rows, dest []any, value reflect.Type, indexes [][]int, dbConfig *DatabaseConfig, query model.Query) iter.Seq2[T, error] {
 	return func(yield func(T, error) bool) {
 		var (
 			s, f reflect.Value
 		)
 		defer rows.Close()
 		s = reflect.New(value).Elem()
+		// the embedded pointers are allocated for each row
+		fresh := slices.ContainsFunc(indexes, func(index []int) bool { return embeddedPointer(value, index) })
 
 		for rows.Next() {
 			query.Header.Err = rows.Scan(dest...)
@@ -102,14 +117,45 @@ func mapStructQuery[T any](ctx context.Context, rows Rows, dest []any, value ref
 				return
 			}
 
+			if fresh {
+				s = reflect.New(value).Elem()
+			}
 			for i, a := range dest {
-				f = s.Field(i)
-				f.Set(reflect.ValueOf(a).Elem())
+				f = fieldByIndex(s, indexes[i])
+				f.Set(destValue(a).Elem())
 			}
 			if !yield(s.Interface().(T), nil) {
 				return
 			}
 		}
+		if query.Header.Err = rowsErr(rows); query.Header.Err != nil {
+			var v T
+			yield(v, dbConfig.ErrorQueryHandler(ctx, query))
+		}
+	}
+}
+
+// scanStructQuery scans the rows using a generated scanner, without reflection
+func scanStructQuery[T any](ctx context.Context, rows Rows, scanner *Scanner[T], fields []fieldSelect, dbConfig *DatabaseConfig, query model.Query) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+
+		for rows.Next() {
+			var v T
+			query.Header.Err = rows.Scan(dbConfig.codecDest(scanner.Dest(&v), fields)...)
+
+			if query.Header.Err != nil {
+				yield(v, dbConfig.ErrorQueryHandler(ctx, query))
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if query.Header.Err = rowsErr(rows); query.Header.Err != nil {
+			var v T
+			yield(v, dbConfig.ErrorQueryHandler(ctx, query))
+		}
 	}
 }
 
@@ -133,32 +179,71 @@ func mapAnonymousStructQuery[T any](ctx context.Context, rows Rows, dest []any,
 			for i, a := range dest {
 				f = s.Field(i)
 				if fieldMap[i] {
-					f.Set(reflect.ValueOf(a))
+					f.Set(destValue(a))
 					continue
 				}
-				f.Set(reflect.ValueOf(a).Elem())
+				f.Set(destValue(a).Elem())
 			}
 			if !yield(s.Interface().(T), nil) {
 				return
 			}
 		}
+		if query.Header.Err = rowsErr(rows); query.Header.Err != nil {
+			var v T
+			yield(v, dbConfig.ErrorQueryHandler(ctx, query))
+		}
 	}
 }
 
-func wrapperQuery(ctx context.Context, conn Connection, query *model.Query) (Rows, error) {
+func wrapperQuery(ctx context.Context, conn Connection, query *model.Query, dbConfig *DatabaseConfig) (Rows, error) {
+	if err := dbConfig.encodeArguments(query); err != nil {
+		return nil, err
+	}
+	dbConfig.commentQuery(ctx, query)
+	ctx, span := dbConfig.startQuery(ctx, query)
+
 	queryStart := time.Now()
-	defer func() { query.Header.QueryDuration = time.Since(queryStart) }()
-	return conn.QueryContext(ctx, query)
+	rows, err := conn.QueryContext(ctx, query)
+	query.Header.QueryDuration = time.Since(queryStart)
+
+	if span == nil {
+		return rows, err
+	}
+	if err != nil {
+		span.end(-1, err)
+		return rows, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
 }
 
-func wrapperQueryRow(ctx context.Context, conn Connection, query *model.Query) Row {
+func wrapperQueryRow(ctx context.Context, conn Connection, query *model.Query, dbConfig *DatabaseConfig) Row {
+	if err := dbConfig.encodeArguments(query); err != nil {
+		return errRow{err: err}
+	}
+	dbConfig.commentQuery(ctx, query)
+	ctx, span := dbConfig.startQuery(ctx, query)
+
 	queryStart := time.Now()
-	defer func() { query.Header.QueryDuration = time.Since(queryStart) }()
-	return conn.QueryRowContext(ctx, query)
+	row := conn.QueryRowContext(ctx, query)
+	query.Header.QueryDuration = time.Since(queryStart)
+
+	if span == nil {
+		return row
+	}
+	return tracedRow{Row: row, span: span}
 }
 
-func wrapperExec(ctx context.Context, conn Connection, query *model.Query) error {
+func wrapperExec(ctx context.Context, conn Connection, query *model.Query, dbConfig *DatabaseConfig) error {
+	if err := dbConfig.encodeArguments(query); err != nil {
+		return err
+	}
+	dbConfig.commentQuery(ctx, query)
+	ctx, span := dbConfig.startQuery(ctx, query)
+
 	queryStart := time.Now()
-	defer func() { query.Header.QueryDuration = time.Since(queryStart) }()
-	return conn.ExecContext(ctx, query)
+	err := conn.ExecContext(ctx, query)
+	query.Header.QueryDuration = time.Since(queryStart)
+
+	span.end(-1, err)
+	return err
 }
@@ -0,0 +1,145 @@
+package goe
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe/model"
+)
+
+type logEntry struct {
+	level string
+	msg   string
+	kv    []any
+}
+
+// value returns the value of the key on the log
+func (e logEntry) value(key string) (any, bool) {
+	for i := 0; i+1 < len(e.kv); i += 2 {
+		if e.kv[i] == key {
+			return e.kv[i+1], true
+		}
+	}
+	return nil, false
+}
+
+// fakeLogger sends the logs to a channel, so the tests can wait the logs made in background
+type fakeLogger struct {
+	entries chan logEntry
+}
+
+func newFakeLogger() *fakeLogger {
+	return &fakeLogger{entries: make(chan logEntry, 100)}
+}
+
+func (l *fakeLogger) InfoContext(ctx context.Context, msg string, kv ...any) {
+	l.entries <- logEntry{level: "info", msg: msg, kv: kv}
+}
+
+func (l *fakeLogger) WarnContext(ctx context.Context, msg string, kv ...any) {
+	l.entries <- logEntry{level: "warn", msg: msg, kv: kv}
+}
+
+func (l *fakeLogger) ErrorContext(ctx context.Context, msg string, kv ...any) {
+	l.entries <- logEntry{level: "error", msg: msg, kv: kv}
+}
+
+func (l *fakeLogger) next(t *testing.T) logEntry {
+	t.Helper()
+	select {
+	case e := <-l.entries:
+		return e
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a log, got none")
+	}
+	return logEntry{}
+}
+
+type LogAnimal struct {
+	Id       int
+	Name     string
+	Password string `goe:"sensitive"`
+}
+
+type LogDatabase struct {
+	LogAnimal *LogAnimal
+	*DB
+}
+
+func TestExplainSlowQueries(t *testing.T) {
+	logger := newFakeLogger()
+	release := make(chan struct{})
+	explained := make(chan model.Query, 1)
+
+	driver := newFakeDriver("SQLite")
+	driver.rows = [][]any{{1, "Cat", "secret"}}
+	driver.config = DatabaseConfig{Logger: logger, QueryThreshold: time.Nanosecond, ExplainSlowQueries: true}
+	driver.config.explain = func(ctx context.Context, query *model.Query) (string, error) {
+		<-release
+		explained <- *query
+		return "SCAN log_animals", ctx.Err()
+	}
+	db, err := Open[LogDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	animals, err := SelectContext(ctx, db.LogAnimal).From(db.LogAnimal).AsSlice()
+	if err != nil || len(animals) != 1 {
+		t.Fatalf("Expected select without waiting the explain, got %v and error: %v", animals, err)
+	}
+	cancel()
+	if warn := logger.next(t); warn.level != "warn" || warn.msg != "query_threshold" {
+		t.Errorf("Expected the warning before the explain, got %+v", warn)
+	}
+
+	close(release)
+	warn := logger.next(t)
+	if plan, _ := warn.value("query_plan"); warn.level != "warn" || warn.msg != "query_plan" || plan != "SCAN log_animals" {
+		t.Errorf("Expected warning with the query plan, got %+v", warn)
+	}
+	if query := <-explained; query.Type != driver.lastQuery().Type || len(query.Attributes) != 3 {
+		t.Errorf("Expected explain of the select, got %+v", query)
+	}
+}
+
+func TestExplainLimit(t *testing.T) {
+	logger := newFakeLogger()
+	release := make(chan struct{})
+	var explains atomic.Int32
+
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Logger: logger, QueryThreshold: time.Nanosecond, ExplainSlowQueries: true}
+	driver.config.explain = func(ctx context.Context, query *model.Query) (string, error) {
+		explains.Add(1)
+		<-release
+		return "SCAN log_animals", nil
+	}
+	db, err := Open[LogDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	for range maxExplains + 2 {
+		if _, err := Select(db.LogAnimal).From(db.LogAnimal).AsSlice(); err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		if warn := logger.next(t); warn.msg != "query_threshold" {
+			t.Fatalf("Expected the slow query logged, got %+v", warn)
+		}
+	}
+	close(release)
+	for range maxExplains {
+		if warn := logger.next(t); warn.msg != "query_plan" {
+			t.Errorf("Expected the query plan, got %+v", warn)
+		}
+	}
+	if n := explains.Load(); n != maxExplains {
+		t.Errorf("Expected %v explains, got %v", maxExplains, n)
+	}
+}
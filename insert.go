@@ -51,7 +51,12 @@ func (c *create[T]) ByValue(value T) (*T, error) {
 		return nil, err
 	}
 
-	return Find(c.table).OnTransaction(c.tx).ById(value)
+	return Find(c.table).OnPrimary().OnTransaction(c.tx).ById(value)
+}
+
+// ToSQL returns the sql and arguments of [create.ByValue] insert, without running the query.
+func (c *create[T]) ToSQL(value T) (string, []any, error) {
+	return c.insert.ToSQL(&value)
 }
 
 // Insert inserts a new record into the given table.
@@ -75,7 +80,7 @@ func Insert[T any](table *T) *stateInsert[T] {
 // See [Insert] for examples.
 func InsertContext[T any](ctx context.Context, table *T) *stateInsert[T] {
 	var state *stateInsert[T] = createInsertState[T](ctx)
-	state.builder.fields, state.err = getArgsTable(addrMap.mapField, table)
+	state.builder.fields, state.err = getArgsTable(fieldsOf(table), table)
 	return state
 }
 
@@ -108,6 +113,23 @@ func (s *stateInsert[T]) One(value *T) error {
 	return handlerValues(s.ctx, s.conn, s.builder.query, driver.GetDatabaseConfig())
 }
 
+// ToSQL returns the sql and arguments of the insert of value, without running the query.
+func (s *stateInsert[T]) ToSQL(value *T) (string, []any, error) {
+	if s.err != nil {
+		return "", nil, s.err
+	}
+
+	if value == nil {
+		return "", nil, errors.New("goe: invalid insert value. try sending a pointer to a struct as value")
+	}
+
+	b := s.builder.copy()
+	b.inserts = nil
+	b.buildSqlInsert(reflect.ValueOf(value).Elem())
+
+	return render(b.fields[0].getDb().driver, &b.query)
+}
+
 func (s *stateInsert[T]) All(value []T) error {
 	if len(value) == 0 {
 		return errors.New("goe: can't insert a empty batch value")
@@ -141,13 +163,10 @@ func getArgsTable[T any](addrMap map[uintptr]field, table *T) ([]field, error) {
 	}
 
 	var fieldOf reflect.Value
-	for i := 0; i < valueOf.NumField(); i++ {
-		fieldOf = valueOf.Field(i)
-		if fieldOf.Kind() == reflect.Slice && fieldOf.Type().Elem().Kind() == reflect.Struct {
-			continue
-		}
+	for _, field := range structFields(valueOf.Type()) {
+		fieldOf = valueOf.FieldByIndex(field.Index)
 		addr := uintptr(fieldOf.Addr().UnsafePointer())
-		if addrMap[addr] != nil {
+		if addrMap[addr] != nil && addrMap[addr].getMode().canInsert() {
 			fields = append(fields, addrMap[addr])
 		}
 	}
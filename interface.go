@@ -3,6 +3,8 @@ package goe
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/go-goe/goe/model"
 )
@@ -11,8 +13,12 @@ type field interface {
 	fieldSelect
 	fieldDb
 	isPrimaryKey() bool
+	isSensitive() bool
+	getMode() fieldMode
+	getCodec() *Codec
+	isJSONColumn() bool
 	getTableId() int
-	getFieldId() int
+	getFieldId() []int
 	getAttributeName() string
 	table() string
 	buildAttributeInsert(*builder)
@@ -42,6 +48,69 @@ type Driver interface {
 	Config
 }
 
+// The interfaces below are optional capabilities of a [Driver], goe checks them
+// with a type assertion and returns a error wrapping [errors.ErrUnsupported]
+// if the driver don't implement the capability used.
+
+// MigratePlanner returns the pending migrate operations, used by [MigratePlan]
+type MigratePlanner interface {
+	MigratePlanContext(context.Context, *Migrator) ([]MigrateOperation, error)
+}
+
+// Introspector reads the current schema from the database, used by [Diff]
+type Introspector interface {
+	// IntrospectContext returns the tables, columns, indexes and foreign keys
+	// found on the database, the data types are the ones used by the database.
+	IntrospectContext(context.Context) (*Migrator, error)
+	// DataType returns the database type used to migrate a mapped data type.
+	DataType(string) string
+}
+
+// SchemaChanger changes tables, columns and indexes, used by [RenameTable],
+// [AlterColumnType], [SetNullable], [SetDefault], [DropIndex] and [RenameIndex]
+type SchemaChanger interface {
+	RenameTable(table, newTable string) error
+	AlterColumnType(table, column, dataType, using string) error
+	SetNullable(table, column string, nullable bool) error
+	SetDefault(table, column, value string) error
+	DropIndex(table, index string) error
+	RenameIndex(table, oldIndex, newIndex string) error
+}
+
+// Renderer returns the sql and arguments the driver runs for the query,
+// used by ToSQL and the keys of the select cache
+type Renderer interface {
+	Render(*model.Query) (string, []any)
+}
+
+// Explainer returns the plan of the query; EXPLAIN on PostgreSQL and EXPLAIN QUERY PLAN on SQLite
+type Explainer interface {
+	ExplainContext(context.Context, *model.Query) (string, error)
+}
+
+// ErrorClassifier returns a [*DatabaseError] for the native errors known by the driver,
+// the other errors are returned unchanged
+type ErrorClassifier interface {
+	ClassifyError(error) error
+}
+
+// StatementCacher returns the stats of the prepared statement cache, used by [DB.StatementStats]
+type StatementCacher interface {
+	StatementStats() StatementStats
+}
+
+// JSONQuerier is implemented by the drivers that render the json operations,
+// like where.JSONPathEquals and function.JSONExtract
+type JSONQuerier interface {
+	SupportsJSONQueries() bool
+}
+
+// QueryTagger is implemented by the drivers that write the [model.Query] comment,
+// used by [DatabaseConfig.QueryTags]
+type QueryTagger interface {
+	SupportsQueryTags() bool
+}
+
 type Config interface {
 	Name() string
 	GetDatabaseConfig() *DatabaseConfig
@@ -74,3 +143,21 @@ type Rows interface {
 type Row interface {
 	Scan(dest ...any) error
 }
+
+// unsupported returns the error of a capability that the driver don't implement
+func unsupported(driver Driver, capability string) error {
+	return fmt.Errorf("goe: driver %v don't support %v: %w", driver.Name(), capability, errors.ErrUnsupported)
+}
+
+// render returns the sql and arguments of the query rendered by the driver
+func render(driver Driver, query *model.Query) (string, []any, error) {
+	renderer, ok := driver.(Renderer)
+	if !ok {
+		return "", nil, unsupported(driver, "Render")
+	}
+	if err := driver.GetDatabaseConfig().encodeValues(query); err != nil {
+		return "", nil, err
+	}
+	sql, args := renderer.Render(query)
+	return sql, args, nil
+}
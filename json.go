@@ -0,0 +1,135 @@
+package goe
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/go-goe/goe/enum"
+	"github.com/go-goe/goe/model"
+)
+
+// jsonColumns are the column types of the fields with the json tag, by driver name
+var jsonColumns = map[string]string{"PostgreSQL": "jsonb", "": "text"}
+
+// jsonPathPattern matches the json paths like $.user.id or $.labels[0],
+// the drivers write the path inside the sql
+var jsonPathPattern = regexp.MustCompile(`^\$(\.\w+|\[\d+\])*$`)
+
+// isJSON reports if the field have the json tag, the value is stored as json
+func isJSON(field reflect.StructField) bool {
+	return tagValueExist(field.Tag.Get("goe"), "json")
+}
+
+// isJSONNullable reports if the json field is a map or slice,
+// the nil values are stored as NULL so the column is migrated as nullable
+func isJSONNullable(field reflect.StructField) bool {
+	kind := field.Type.Kind()
+	return isJSON(field) && (kind == reflect.Map || kind == reflect.Slice)
+}
+
+// jsonColumn returns the column type of a json field for the driver
+func jsonColumn(driverName string) string {
+	if column, ok := jsonColumns[driverName]; ok {
+		return column
+	}
+	return jsonColumns[""]
+}
+
+// jsonFieldCodec returns the json codec of the column of a json field, nil if the field is not json.
+// Strings and byte slices are already json, they are stored without a codec
+func jsonFieldCodec(field reflect.StructField) *Codec {
+	if !isJSON(field) {
+		return nil
+	}
+	typeOf := field.Type
+	if typeOf.Kind() == reflect.Pointer {
+		typeOf = typeOf.Elem()
+	}
+	if typeOf.Kind() == reflect.String || (typeOf.Kind() == reflect.Slice && typeOf.Elem().Kind() == reflect.Uint8) {
+		return nil
+	}
+	return jsonCodec(typeOf)
+}
+
+func jsonCodec(typeOf reflect.Type) *Codec {
+	return &Codec{
+		typeOf:  typeOf,
+		columns: jsonColumns,
+		encode: func(v any) (any, error) {
+			// a nil map or slice is stored as NULL, like a nil pointer
+			if value := reflect.ValueOf(v); (value.Kind() == reflect.Map || value.Kind() == reflect.Slice) && value.IsNil() {
+				return nil, nil
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return string(data), nil
+		},
+		decode: func(src any) (any, error) {
+			var data []byte
+			switch v := src.(type) {
+			case []byte:
+				data = v
+			case string:
+				data = []byte(v)
+			default:
+				return nil, fmt.Errorf("goe: invalid json value of type %T", src)
+			}
+			value := reflect.New(typeOf)
+			if err := json.Unmarshal(data, value.Interface()); err != nil {
+				return nil, err
+			}
+			return value.Elem().Interface(), nil
+		},
+	}
+}
+
+// checkJSONPath returns a error if path is not a valid json path
+func checkJSONPath(path string) error {
+	if !jsonPathPattern.MatchString(path) {
+		return fmt.Errorf("goe: invalid json path %q. try a path like $.key or $.key[0]", path)
+	}
+	return nil
+}
+
+// jsonTarget returns a error if a is not a json field or the driver can't render the json queries
+func jsonTarget(a field) error {
+	if !a.isJSONColumn() {
+		return fmt.Errorf("goe: invalid json operation, the column %v don't have the json tag", a.getAttributeName())
+	}
+	driver := a.getDb().driver
+	if querier, ok := driver.(JSONQuerier); !ok || !querier.SupportsJSONQueries() {
+		return unsupported(driver, "json queries")
+	}
+	return nil
+}
+
+// jsonOperation checks the target and the path of the json operations and marshals the value of JSONContains
+func jsonOperation(a field, br *model.Operation) error {
+	switch br.Operator {
+	case enum.JSONPathEquals, enum.JSONHasKey:
+		if err := jsonTarget(a); err != nil {
+			return err
+		}
+		return checkJSONPath(br.JSONPath)
+	case enum.JSONContains:
+		if err := jsonTarget(a); err != nil {
+			return err
+		}
+		data, err := json.Marshal(br.Value.GetValue())
+		if err != nil {
+			return fmt.Errorf("goe: invalid json value: %w", err)
+		}
+		br.Value = jsonValue(data)
+	}
+	return nil
+}
+
+type jsonValue string
+
+func (v jsonValue) GetValue() any {
+	return string(v)
+}
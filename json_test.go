@@ -0,0 +1,259 @@
+package goe
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-goe/goe/model"
+	"github.com/go-goe/goe/query"
+	"github.com/go-goe/goe/query/function"
+	"github.com/go-goe/goe/query/where"
+)
+
+type JSONColor struct {
+	Name string `json:"name"`
+}
+
+type JSONEvent struct {
+	Id     int
+	Tags   map[string]string `goe:"json"`
+	Labels map[string]string
+	Color  *JSONColor `goe:"json"`
+}
+
+type JSONDatabase struct {
+	JSONEvent *JSONEvent
+	*DB
+}
+
+// labelsCodec stores the maps as key=value pairs, a codec of the same type of a json field
+var labelsCodec = NewCodec(map[string]string{"": "text"},
+	func(m map[string]string) (any, error) {
+		pairs := make([]string, 0, len(m))
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			pairs = append(pairs, k+"="+m[k])
+		}
+		return strings.Join(pairs, ","), nil
+	},
+	func(src any) (map[string]string, error) {
+		s, ok := src.(string)
+		if !ok {
+			return nil, errors.New("invalid labels")
+		}
+		m := make(map[string]string)
+		for _, pair := range strings.Split(s, ",") {
+			k, v, _ := strings.Cut(pair, "=")
+			m[k] = v
+		}
+		return m, nil
+	},
+)
+
+func TestJSONColumns(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Codecs: []Codec{labelsCodec}}
+	db, err := Open[JSONDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	t.Run("Insert", func(t *testing.T) {
+		driver.rows = [][]any{{1}}
+		defer func() { driver.rows = nil }()
+
+		err := Insert(db.JSONEvent).One(&JSONEvent{
+			Tags:   map[string]string{"a": "1"},
+			Labels: map[string]string{"a": "1"},
+			Color:  &JSONColor{Name: "red"},
+		})
+		if err != nil {
+			t.Fatalf("Expected insert, got error: %v", err)
+		}
+		want := []any{`{"a":"1"}`, "a=1", `{"name":"red"}`}
+		if args := driver.lastQuery().Arguments; !slices.Equal(args, want) {
+			t.Errorf("Expected json on the json columns and the codec on labels %v, got %v", want, args)
+		}
+	})
+
+	t.Run("InsertNil", func(t *testing.T) {
+		driver.rows = [][]any{{1}}
+		defer func() { driver.rows = nil }()
+
+		if err := Insert(db.JSONEvent).One(&JSONEvent{Labels: map[string]string{"a": "1"}}); err != nil {
+			t.Fatalf("Expected insert, got error: %v", err)
+		}
+		want := []any{nil, "a=1", nil}
+		if args := driver.lastQuery().Arguments; !slices.Equal(args, want) {
+			t.Errorf("Expected NULL of the nil map and pointer %v, got %v", want, args)
+		}
+	})
+
+	t.Run("Where", func(t *testing.T) {
+		_, err := Select(db.JSONEvent).From(db.JSONEvent).Wheres(
+			where.Equals(&db.JSONEvent.Tags, map[string]string{"a": "1"}),
+			where.And(),
+			where.Equals(&db.JSONEvent.Labels, map[string]string{"a": "1"}),
+		).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		want := []any{`{"a":"1"}`, "a=1"}
+		if args := driver.lastQuery().Arguments; !slices.Equal(args, want) {
+			t.Errorf("Expected the where arguments encoded by column %v, got %v", want, args)
+		}
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		driver.rows = [][]any{{1, `{"a":"1"}`, "b=2", `{"name":"red"}`}, {2, nil, "c=3", nil}}
+		defer func() { driver.rows = nil }()
+
+		events, err := Select(db.JSONEvent).From(db.JSONEvent).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		if events[0].Tags["a"] != "1" || events[0].Labels["b"] != "2" || events[0].Color == nil || events[0].Color.Name != "red" {
+			t.Errorf("Expected the columns decoded, got %+v", events[0])
+		}
+		if events[1].Tags != nil || events[1].Labels["c"] != "3" || events[1].Color != nil {
+			t.Errorf("Expected NULL decoded as nil, got %+v", events[1])
+		}
+	})
+}
+
+func TestJSONMigrate(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Codecs: []Codec{labelsCodec}}
+	db, err := Open[JSONDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	m := migrateFrom(db, db.driver)
+	if m.Error != nil {
+		t.Fatalf("Expected migrator, got error: %v", m.Error)
+	}
+	table := m.Tables["j_s_o_n_events"]
+	if table == nil {
+		t.Fatalf("Expected the events table, got %v", m.Tables)
+	}
+	nullable := make(map[string]bool)
+	for _, a := range table.Attributes {
+		nullable[a.Name] = a.Nullable
+	}
+	if !nullable["tags"] || nullable["labels"] || !nullable["color"] {
+		t.Errorf("Expected the json map and pointer nullable and the codec map not null, got %v", nullable)
+	}
+}
+
+func TestJSONReplica(t *testing.T) {
+	primary, replica := newFakeDriver("primary"), newFakeDriver("replica")
+	primary.config = DatabaseConfig{Codecs: []Codec{labelsCodec}}
+	replica.rows = [][]any{{1, `{"a":"1"}`, "b=2", `{"name":"red"}`}}
+	db, err := OpenReplicas[JSONDatabase](primary, Replica{Driver: replica})
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	events, err := Select(db.JSONEvent).From(db.JSONEvent).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	if replica.queryCount() != 1 || events[0].Tags["a"] != "1" || events[0].Labels["b"] != "2" || events[0].Color.Name != "red" {
+		t.Errorf("Expected the columns decoded on the replica, got %+v", events[0])
+	}
+}
+
+// jsonDriver is a fake driver that renders the json queries
+type jsonDriver struct {
+	*fakeDriver
+}
+
+func (d jsonDriver) SupportsJSONQueries() bool {
+	return true
+}
+
+func TestJSONQueries(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Codecs: []Codec{labelsCodec}}
+	db, err := Open[JSONDatabase](jsonDriver{driver})
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	testCases := []struct {
+		desc    string
+		where   model.Operation
+		wantErr bool
+	}{
+		{desc: "PathEquals", where: where.JSONPathEquals(&db.JSONEvent.Color, "$.name", "red")},
+		{desc: "Contains", where: where.JSONContains(&db.JSONEvent.Tags, map[string]string{"a": "1"})},
+		{desc: "HasKey", where: where.JSONHasKey(&db.JSONEvent.Tags, "$.a")},
+		{desc: "NotJSONColumn", where: where.JSONHasKey(&db.JSONEvent.Labels, "$.a"), wantErr: true},
+		{desc: "InvalidPath", where: where.JSONHasKey(&db.JSONEvent.Tags, "a"), wantErr: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := Select(db.JSONEvent).From(db.JSONEvent).Wheres(tC.where).AsSlice()
+			if (err != nil) != tC.wantErr {
+				t.Errorf("Expected error %v, got %v", tC.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("Extract", func(t *testing.T) {
+		_, err := Select(&struct{ Name *query.JSON[string] }{
+			Name: function.JSONExtract[string](&db.JSONEvent.Color, "$.name"),
+		}).From(db.JSONEvent).AsSlice()
+		if err != nil {
+			t.Errorf("Expected select, got error: %v", err)
+		}
+
+		_, err = Select(&struct{ Name *query.JSON[string] }{
+			Name: function.JSONExtract[string](&db.JSONEvent.Labels, "$.name"),
+		}).From(db.JSONEvent).AsSlice()
+		if err == nil {
+			t.Errorf("Expected error of json extract on a column without the json tag, got nil")
+		}
+	})
+}
+
+func TestJSONUnsupported(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Codecs: []Codec{labelsCodec}}
+	db, err := Open[JSONDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	_, err = Select(db.JSONEvent).From(db.JSONEvent).Wheres(where.JSONHasKey(&db.JSONEvent.Tags, "$.a")).AsSlice()
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected unsupported error, got %v", err)
+	}
+	_, err = Select(&struct{ Name *query.JSON[string] }{
+		Name: function.JSONExtract[string](&db.JSONEvent.Color, "$.name"),
+	}).From(db.JSONEvent).AsSlice()
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected unsupported error of json extract, got %v", err)
+	}
+}
+
+func TestJSONExtractString(t *testing.T) {
+	for _, src := range []any{`"red"`, []byte(`"red"`)} {
+		var j query.JSON[string]
+		if err := j.Scan(src); err != nil || j.Value != `"red"` {
+			t.Errorf("Expected the raw text of %v, got %q and error %v", src, j.Value, err)
+		}
+	}
+	var j query.JSON[int]
+	if err := j.Scan([]byte("42")); err != nil || j.Value != 42 {
+		t.Errorf("Expected 42, got %v and error %v", j.Value, err)
+	}
+}
@@ -0,0 +1,212 @@
+package goe
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-goe/goe/enum"
+)
+
+// MetricsCollector receives the metrics of every query runned by goe,
+// it's set on [DatabaseConfig]. Collect is called from many goroutines.
+type MetricsCollector interface {
+	Collect(QueryMetric)
+}
+
+// QueryMetric is the metric of one query sent to a [MetricsCollector].
+type QueryMetric struct {
+	Database   string
+	Type       enum.QueryType
+	Table      string        // first table of the query, empty on raw queries
+	Duration   time.Duration // on selects until the rows are closed, including the scan
+	Rows       int64         // rows returned by the query, -1 if unknown
+	ErrorClass string        // empty if the query succeeded
+}
+
+var queryTypes = map[enum.QueryType]string{
+	enum.SelectQuery: "select",
+	enum.InsertQuery: "insert",
+	enum.UpdateQuery: "update",
+	enum.DeleteQuery: "delete",
+	enum.RawQuery:    "raw",
+}
+
+// ErrorClass returns a short class of err used as metric label;
+// empty for nil errors.
+func ErrorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrUniqueViolation):
+		return "unique_violation"
+	case errors.Is(err, ErrForeignKeyViolation):
+		return "foreign_key_violation"
+	case errors.Is(err, ErrNotNullViolation):
+		return "not_null_violation"
+	case errors.Is(err, ErrCheckViolation):
+		return "check_violation"
+	case errors.Is(err, ErrSerialization):
+		return "serialization"
+	case errors.Is(err, ErrDeadlock):
+		return "deadlock"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, sql.ErrNoRows):
+		return "no_rows"
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, sql.ErrTxDone):
+		return "connection"
+	}
+	return "error"
+}
+
+// unquote removes the keyword escape added by the driver on table names
+func unquote(table string) string {
+	return strings.Trim(table, "\"`[]")
+}
+
+// DefaultDurationBuckets are the upper bounds in seconds of the query duration histogram.
+var DefaultDurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type metricKey struct {
+	database   string
+	operation  string
+	table      string
+	errorClass string
+}
+
+type metricSeries struct {
+	count   uint64
+	rows    int64
+	sum     float64
+	buckets []uint64
+}
+
+// MemoryMetrics is a in-memory [MetricsCollector] aggregating
+// the queries by database, operation, table and error class.
+//
+// # Example
+//
+//	metrics := goe.NewMemoryMetrics()
+//	db, err := goe.Open[Database](postgres.Open(dsn, postgres.Config{
+//		DatabaseConfig: goe.DatabaseConfig{Metrics: metrics},
+//	}))
+//
+//	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//		metrics.WritePrometheus(w)
+//	})
+type MemoryMetrics struct {
+	mu      sync.Mutex
+	buckets []float64
+	series  map[metricKey]*metricSeries
+}
+
+// NewMemoryMetrics returns a [MemoryMetrics] using the [DefaultDurationBuckets].
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{buckets: DefaultDurationBuckets, series: make(map[metricKey]*metricSeries)}
+}
+
+func (m *MemoryMetrics) Collect(q QueryMetric) {
+	key := metricKey{database: q.Database, operation: queryTypes[q.Type], table: q.Table, errorClass: q.ErrorClass}
+	seconds := q.Duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.series[key]
+	if s == nil {
+		s = &metricSeries{buckets: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+	s.count++
+	s.sum += seconds
+	if q.Rows > 0 {
+		s.rows += q.Rows
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			s.buckets[i]++
+		}
+	}
+}
+
+// Reset removes all the collected metrics.
+func (m *MemoryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series = make(map[metricKey]*metricSeries)
+}
+
+// WritePrometheus writes the collected metrics on Prometheus text format.
+func (m *MemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	keys := make([]metricKey, 0, len(m.series))
+	series := make(map[metricKey]metricSeries, len(m.series))
+	for k, s := range m.series {
+		keys = append(keys, k)
+		series[k] = metricSeries{count: s.count, rows: s.rows, sum: s.sum, buckets: slices.Clone(s.buckets)}
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(keys, func(a, b metricKey) int {
+		return cmp.Or(
+			strings.Compare(a.database, b.database),
+			strings.Compare(a.operation, b.operation),
+			strings.Compare(a.table, b.table),
+			strings.Compare(a.errorClass, b.errorClass),
+		)
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP goe_queries_total Total of queries runned by goe.\n")
+	b.WriteString("# TYPE goe_queries_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "goe_queries_total{%v} %v\n", k.labels(), series[k].count)
+	}
+
+	b.WriteString("# HELP goe_query_rows_total Total of rows returned by the queries.\n")
+	b.WriteString("# TYPE goe_query_rows_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "goe_query_rows_total{%v} %v\n", k.labels(), series[k].rows)
+	}
+
+	b.WriteString("# HELP goe_query_duration_seconds Duration of the queries in seconds.\n")
+	b.WriteString("# TYPE goe_query_duration_seconds histogram\n")
+	for _, k := range keys {
+		s := series[k]
+		labels := k.labels()
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&b, "goe_query_duration_seconds_bucket{%v,le=\"%v\"} %v\n", labels, strconv.FormatFloat(bound, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(&b, "goe_query_duration_seconds_bucket{%v,le=\"+Inf\"} %v\n", labels, s.count)
+		fmt.Fprintf(&b, "goe_query_duration_seconds_sum{%v} %v\n", labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "goe_query_duration_seconds_count{%v} %v\n", labels, s.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (k metricKey) labels() string {
+	return fmt.Sprintf("database=%v,operation=%v,table=%v,error=%v",
+		labelValue(k.database), labelValue(k.operation), labelValue(k.table), labelValue(k.errorClass))
+}
+
+// labelEscaper escapes the label values as the Prometheus text format,
+// only the backslash, the double quote and the line feed are escaped
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelValue returns the quoted label value
+func labelValue(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
@@ -0,0 +1,105 @@
+package goe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe/enum"
+)
+
+type MetricAnimal struct {
+	Id   int
+	Name string
+}
+
+type MetricDatabase struct {
+	MetricAnimal *MetricAnimal
+	*DB
+}
+
+// durationMetrics keeps the metrics sent to the collector
+type durationMetrics struct {
+	*MemoryMetrics
+	metrics []QueryMetric
+}
+
+func (m *durationMetrics) Collect(q QueryMetric) {
+	m.metrics = append(m.metrics, q)
+	m.MemoryMetrics.Collect(q)
+}
+
+func TestMemoryMetrics(t *testing.T) {
+	metrics := &durationMetrics{MemoryMetrics: NewMemoryMetrics()}
+	driver := newFakeDriver("SQLite")
+	driver.rows = [][]any{{1, "Cat"}, {2, "Dog"}}
+	driver.config = DatabaseConfig{Metrics: metrics}
+	db, err := Open[MetricDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	for _, err := range Select(db.MetricAnimal).From(db.MetricAnimal).Rows() {
+		if err != nil {
+			t.Fatalf("Expected row, got error: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(metrics.metrics) != 1 {
+		t.Fatalf("Expected one metric, got %v", metrics.metrics)
+	}
+	if q := metrics.metrics[0]; q.Type != enum.SelectQuery || q.Table != "metric_animals" || q.Rows != 2 || q.Duration < 10*time.Millisecond {
+		t.Errorf("Expected select metric with the scan duration, got %+v", q)
+	}
+
+	driver.err = errors.New("syntax error")
+	_, _ = Select(db.MetricAnimal).From(db.MetricAnimal).AsSlice()
+	driver.err = nil
+
+	var b strings.Builder
+	if err = metrics.WritePrometheus(&b); err != nil {
+		t.Fatalf("Expected prometheus metrics, got error: %v", err)
+	}
+	for _, want := range []string{
+		`goe_queries_total{database="SQLite",operation="select",table="metric_animals",error=""} 1`,
+		`goe_queries_total{database="SQLite",operation="select",table="metric_animals",error="error"} 1`,
+		`goe_query_rows_total{database="SQLite",operation="select",table="metric_animals",error=""} 2`,
+		`goe_query_duration_seconds_bucket{database="SQLite",operation="select",table="metric_animals",error="",le="0.005"} 0`,
+		`goe_query_duration_seconds_bucket{database="SQLite",operation="select",table="metric_animals",error="",le="+Inf"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected %v on prometheus metrics, got:\n%v", want, b.String())
+		}
+	}
+
+	metrics.Reset()
+	b.Reset()
+	metrics.WritePrometheus(&b)
+	if strings.Contains(b.String(), "metric_animals") {
+		t.Errorf("Expected no metrics after reset, got:\n%v", b.String())
+	}
+}
+
+func TestPrometheusLabels(t *testing.T) {
+	metrics := NewMemoryMetrics()
+	metrics.Collect(QueryMetric{Database: "ab", Type: enum.SelectQuery, Table: "c"})
+	metrics.Collect(QueryMetric{Database: "a", Type: enum.SelectQuery, Table: "bc"})
+	metrics.Collect(QueryMetric{Database: `b\"`, Type: enum.RawQuery, Table: "ção\t\n"})
+
+	var b strings.Builder
+	if err := metrics.WritePrometheus(&b); err != nil {
+		t.Fatalf("Expected prometheus metrics, got error: %v", err)
+	}
+	want := `goe_queries_total{database="b\\\"",operation="raw",table="ção` + "\t" + `\n",error=""} 1`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("Expected %v on prometheus metrics, got:\n%v", want, b.String())
+	}
+
+	first := strings.Index(b.String(), `goe_queries_total{database="a",`)
+	second := strings.Index(b.String(), `goe_queries_total{database="ab",`)
+	if first == -1 || second == -1 || first > second {
+		t.Errorf("Expected the series sorted by database first, got:\n%v", b.String())
+	}
+}
@@ -21,6 +21,41 @@ func AutoMigrateContext(ctx context.Context, dbTarget any) error {
 	return db.driver.MigrateContext(ctx, m)
 }
 
+// MigratePlan returns the pending operations that [AutoMigrate] would run,
+// without applying them on the database.
+//
+// MigratePlan uses [context.Background] internally;
+// to specify the context, use [MigratePlanContext].
+//
+// # Example
+//
+//	operations, err := goe.MigratePlan(db)
+//	for _, op := range operations {
+//		fmt.Println(op.Sql)
+//	}
+func MigratePlan(dbTarget any) ([]MigrateOperation, error) {
+	return MigratePlanContext(context.Background(), dbTarget)
+}
+
+// MigratePlanContext returns the pending operations that [AutoMigrateContext] would run,
+// without applying them on the database.
+//
+// See [MigratePlan] for examples.
+func MigratePlanContext(ctx context.Context, dbTarget any) ([]MigrateOperation, error) {
+	db := getDatabase(dbTarget)
+
+	m := migrateFrom(dbTarget, db.driver)
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	planner, ok := db.driver.(MigratePlanner)
+	if !ok {
+		return nil, unsupported(db.driver, "MigratePlan")
+	}
+	return planner.MigratePlanContext(ctx, m)
+}
+
 func DropTable(dbTarget any, table string) error {
 	db := getDatabase(dbTarget)
 
@@ -45,3 +80,105 @@ func RenameColumn(dbTarget any, table, oldColumn, newColumn string) error {
 
 	return db.driver.RenameColumn(table, oldColumn, newColumn)
 }
+
+func RenameTable(dbTarget any, table, newTable string) error {
+	db := getDatabase(dbTarget)
+
+	table = db.driver.KeywordHandler(utils.TableNamePattern(table))
+	newTable = db.driver.KeywordHandler(utils.TableNamePattern(newTable))
+
+	changer, ok := db.driver.(SchemaChanger)
+	if !ok {
+		return unsupported(db.driver, "RenameTable")
+	}
+	return changer.RenameTable(table, newTable)
+}
+
+// AlterColumnType changes the column data type, the data type
+// follows the same rules of the "type" tag.
+//
+// # Example
+//
+//	err = goe.AlterColumnType(db, "User", "Name", "varchar(100)")
+func AlterColumnType(dbTarget any, table, column, dataType string) error {
+	return AlterColumnTypeUsing(dbTarget, table, column, dataType, "")
+}
+
+// AlterColumnTypeUsing changes the column data type converting
+// the current values with the using expression.
+//
+// # Example
+//
+//	err = goe.AlterColumnTypeUsing(db, "User", "Age", "int", "age::integer")
+func AlterColumnTypeUsing(dbTarget any, table, column, dataType, using string) error {
+	db := getDatabase(dbTarget)
+
+	table = db.driver.KeywordHandler(utils.TableNamePattern(table))
+	column = db.driver.KeywordHandler(utils.ColumnNamePattern(column))
+
+	changer, ok := db.driver.(SchemaChanger)
+	if !ok {
+		return unsupported(db.driver, "AlterColumnType")
+	}
+	return changer.AlterColumnType(table, column, dataType, using)
+}
+
+func SetNullable(dbTarget any, table, column string, nullable bool) error {
+	db := getDatabase(dbTarget)
+
+	table = db.driver.KeywordHandler(utils.TableNamePattern(table))
+	column = db.driver.KeywordHandler(utils.ColumnNamePattern(column))
+
+	changer, ok := db.driver.(SchemaChanger)
+	if !ok {
+		return unsupported(db.driver, "SetNullable")
+	}
+	return changer.SetNullable(table, column, nullable)
+}
+
+// SetDefault sets the column default as a SQL expression,
+// a empty value drops the column default.
+//
+// # Example
+//
+//	err = goe.SetDefault(db, "User", "CreatedAt", "CURRENT_TIMESTAMP")
+func SetDefault(dbTarget any, table, column, value string) error {
+	db := getDatabase(dbTarget)
+
+	table = db.driver.KeywordHandler(utils.TableNamePattern(table))
+	column = db.driver.KeywordHandler(utils.ColumnNamePattern(column))
+
+	changer, ok := db.driver.(SchemaChanger)
+	if !ok {
+		return unsupported(db.driver, "SetDefault")
+	}
+	return changer.SetDefault(table, column, value)
+}
+
+// DropIndex drops the index, the index is the name used on database
+// as returned by [MigratePlan] and [Diff].
+func DropIndex(dbTarget any, table, index string) error {
+	db := getDatabase(dbTarget)
+
+	table = db.driver.KeywordHandler(utils.TableNamePattern(table))
+
+	changer, ok := db.driver.(SchemaChanger)
+	if !ok {
+		return unsupported(db.driver, "DropIndex")
+	}
+	return changer.DropIndex(table, db.driver.KeywordHandler(index))
+}
+
+// RenameIndex renames the index, the index is the name used on database
+// as returned by [MigratePlan] and [Diff].
+func RenameIndex(dbTarget any, table, oldIndex, newIndex string) error {
+	db := getDatabase(dbTarget)
+
+	table = db.driver.KeywordHandler(utils.TableNamePattern(table))
+
+	changer, ok := db.driver.(SchemaChanger)
+	if !ok {
+		return unsupported(db.driver, "RenameIndex")
+	}
+	return changer.RenameIndex(table, db.driver.KeywordHandler(oldIndex), db.driver.KeywordHandler(newIndex))
+}
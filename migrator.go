@@ -6,6 +6,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/go-goe/goe/enum"
 	"github.com/go-goe/goe/utils"
 )
 
@@ -23,13 +24,29 @@ type TableMigrate struct {
 	ManyToOnes   []ManyToOneMigrate
 	OneToOnes    []OneToOneMigrate
 	Indexes      []IndexMigrate
+	Checks       []CheckMigrate
+}
+
+type CheckMigrate struct {
+	Name         string
+	EscapingName string
+	Expression   string
 }
 
 type IndexMigrate struct {
 	Name         string
 	EscapingName string
 	Unique       bool
+	Method       string // index method, like btree, hash, gin or gist; empty for database default
+	Where        string // predicate of a partial index
 	Attributes   []AttributeMigrate
+	Columns      []IndexColumnMigrate // options of each attribute, in the same order of Attributes
+	Include      []AttributeMigrate   // non-key columns of a covering index
+}
+
+type IndexColumnMigrate struct {
+	Descending bool
+	Expression string // expression used in place of the attribute, like lower("email")
 }
 
 type PrimaryKeyMigrate struct {
@@ -37,6 +54,7 @@ type PrimaryKeyMigrate struct {
 	Name          string
 	EscapingName  string
 	DataType      string
+	Default       string
 }
 
 type AttributeMigrate struct {
@@ -44,6 +62,7 @@ type AttributeMigrate struct {
 	Name         string
 	EscapingName string
 	DataType     string
+	Default      string
 }
 
 type OneToOneMigrate struct {
@@ -52,6 +71,8 @@ type OneToOneMigrate struct {
 	TargetColumn         string
 	EscapingTargetTable  string
 	EscapingTargetColumn string
+	OnDelete             string // referential action as SQL, empty for database default
+	OnUpdate             string // referential action as SQL, empty for database default
 }
 
 type ManyToOneMigrate struct {
@@ -60,6 +81,17 @@ type ManyToOneMigrate struct {
 	TargetColumn         string
 	EscapingTargetTable  string
 	EscapingTargetColumn string
+	OnDelete             string // referential action as SQL, empty for database default
+	OnUpdate             string // referential action as SQL, empty for database default
+}
+
+// MigrateOperation is a pending change on the database schema,
+// Sql is the statement that the driver runs to apply it
+type MigrateOperation struct {
+	Type  enum.MigrateOperationType
+	Table string
+	Name  string // name of the column, index or foreign key, empty on create table
+	Sql   string
 }
 
 func migrateFrom(db any, driver Driver) *Migrator {
@@ -78,27 +110,44 @@ func migrateFrom(db any, driver Driver) *Migrator {
 }
 
 func typeField(tables reflect.Value, valueOf reflect.Value, migrator *Migrator, driver Driver) error {
-	pks, fieldNames, err := migratePk(valueOf.Type(), driver)
-	if err != nil {
-		return err
-	}
 	table := new(TableMigrate)
 
 	table.Name = utils.TableNamePattern(valueOf.Type().Name())
-	var field reflect.StructField
+	pks, fieldNames, err := migratePk(valueOf.Type(), table, driver)
+	if err != nil {
+		return err
+	}
 
-	for fieldId := range valueOf.NumField() {
-		field = valueOf.Type().Field(fieldId)
+	for _, field := range structFields(valueOf.Type()) {
 		if skipPrimaryKey(fieldNames, field.Name, tables, field) {
 			continue
 		}
-		switch valueOf.Field(fieldId).Kind() {
+		fieldOf := valueOf.FieldByIndex(field.Index)
+		if hasCodec(driver, field, fieldOf) {
+			err = helperAttributeMigrate(body{
+				field:   field,
+				driver:  driver,
+				tables:  tables,
+				valueOf: valueOf,
+				typeOf:  valueOf.Type(),
+				migrate: &infosMigrate{
+					table:      table,
+					field:      field,
+					fieldNames: fieldNames,
+				},
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		switch fieldOf.Kind() {
 		case reflect.Slice:
 			err = handlerSlice(body{
-				fieldId:     fieldId,
+				field:       field,
 				driver:      driver,
 				tables:      tables,
-				fieldTypeOf: valueOf.Field(fieldId).Type().Elem(),
+				fieldTypeOf: fieldOf.Type().Elem(),
 				typeOf:      valueOf.Type(),
 				valueOf:     valueOf,
 				migrate: &infosMigrate{
@@ -112,9 +161,9 @@ func typeField(tables reflect.Value, valueOf reflect.Value, migrator *Migrator,
 			}
 		case reflect.Struct:
 			err = handlerStruct(body{
-				fieldId:     fieldId,
+				field:       field,
 				driver:      driver,
-				fieldTypeOf: valueOf.Field(fieldId).Type(),
+				fieldTypeOf: fieldOf.Type(),
 				valueOf:     valueOf,
 				migrate: &infosMigrate{
 					table: table,
@@ -126,7 +175,7 @@ func typeField(tables reflect.Value, valueOf reflect.Value, migrator *Migrator,
 			}
 		case reflect.Ptr:
 			err = helperAttributeMigrate(body{
-				fieldId:  fieldId,
+				field:    field,
 				driver:   driver,
 				nullable: true,
 				tables:   tables,
@@ -143,7 +192,7 @@ func typeField(tables reflect.Value, valueOf reflect.Value, migrator *Migrator,
 			}
 		default:
 			err = helperAttributeMigrate(body{
-				fieldId: fieldId,
+				field:   field,
 				driver:  driver,
 				tables:  tables,
 				valueOf: valueOf,
@@ -221,71 +270,59 @@ func createOneToOneMigrate(b body, typeOf reflect.Type) any {
 	return mto
 }
 
-func migratePk(typeOf reflect.Type, driver Driver) ([]*PrimaryKeyMigrate, []string, error) {
-	var pks []*PrimaryKeyMigrate
-	var fieldsNames []string
-
-	id, valid := getId(typeOf)
-	if valid {
-		pks = make([]*PrimaryKeyMigrate, 1)
-		fieldsNames = make([]string, 1)
-		pks[0] = createMigratePk(id.Name, isAutoIncrement(id), getTagType(id), driver)
-		fieldsNames[0] = id.Name
-		return pks, fieldsNames, nil
-	}
-
+func migratePk(typeOf reflect.Type, table *TableMigrate, driver Driver) ([]*PrimaryKeyMigrate, []string, error) {
 	fields := fieldsByTags("pk", typeOf)
+	if id, valid := getId(typeOf); valid {
+		fields = []reflect.StructField{id}
+	}
 	if len(fields) == 0 {
 		return nil, nil, fmt.Errorf("goe: struct %q don't have a primary key setted", typeOf.Name())
 	}
 
-	pks = make([]*PrimaryKeyMigrate, len(fields))
-	fieldsNames = make([]string, len(fields))
+	pks := make([]*PrimaryKeyMigrate, len(fields))
+	fieldsNames := make([]string, len(fields))
 	for i := range fields {
-		pks[i] = createMigratePk(fields[i].Name, isAutoIncrement(fields[i]), getTagType(fields[i]), driver)
+		pks[i] = createMigratePk(fields[i].Name, isAutoIncrement(fields[i]), getTagType(fields[i], driver), driver)
+		err := migratePkTags(body{typeOf: typeOf, driver: driver, migrate: &infosMigrate{table: table, field: fields[i]}}, pks[i])
+		if err != nil {
+			return nil, nil, err
+		}
 		fieldsNames[i] = fields[i].Name
 	}
 	return pks, fieldsNames, nil
 }
 
+// migratePkTags sets the default and check values of the primary key from the goe tag,
+// a primary key can't be null or have a default if is auto increment
+func migratePkTags(b body, pk *PrimaryKeyMigrate) error {
+	at := AttributeMigrate{Name: pk.Name}
+	migrateColumnTags(b, &at)
+	if at.Nullable {
+		return fmt.Errorf("goe: struct %q have a null primary key %q, primary keys can't be null", b.typeOf.Name(), b.migrate.field.Name)
+	}
+	if at.Default != "" && pk.AutoIncrement {
+		return fmt.Errorf("goe: struct %q have a default on the auto increment primary key %q", b.typeOf.Name(), b.migrate.field.Name)
+	}
+	pk.Default = at.Default
+	return nil
+}
+
 func migrateAtt(b body) error {
 	at := createMigrateAtt(
 		b.migrate.field.Name,
-		getTagType(b.migrate.field),
-		b.nullable,
+		getTagType(b.migrate.field, b.driver),
+		b.nullable || isJSONNullable(b.migrate.field),
 		b.driver,
 	)
+	migrateColumnTags(b, at)
 	b.migrate.table.Attributes = append(b.migrate.table.Attributes, *at)
 
 	indexFunc := getIndex(b.migrate.field)
 	if indexFunc != "" {
-		for _, index := range strings.Split(indexFunc, ",") {
-			indexName := getIndexValue(index, "n:")
-
-			if indexName == "" {
-				indexName = b.migrate.table.Name + "_idx_" + strings.ToLower(b.migrate.field.Name)
-			}
-			in := IndexMigrate{
-				Name:         b.migrate.table.Name + "_" + indexName,
-				EscapingName: b.driver.KeywordHandler(b.migrate.table.Name + "_" + indexName),
-				Unique:       strings.Contains(index, "unique"),
-				Attributes:   []AttributeMigrate{*at},
-			}
-
-			var i int
-			if i = slices.IndexFunc(b.migrate.table.Indexes, func(i IndexMigrate) bool {
-				return i.Name == in.Name && i.Unique == in.Unique
-			}); i == -1 {
-				if c := slices.IndexFunc(b.migrate.table.Indexes, func(i IndexMigrate) bool {
-					return i.Name == in.Name && i.Unique != in.Unique
-				}); c != -1 {
-					return fmt.Errorf(`goe: struct "%v" have two or more indexes with same name but different uniqueness "%v"`, b.migrate.table.Name, in.Name)
-				}
-
-				b.migrate.table.Indexes = append(b.migrate.table.Indexes, in)
-				continue
+		for _, index := range splitTag(indexFunc, ',') {
+			if err := migrateIndex(b, at, index); err != nil {
+				return err
 			}
-			b.migrate.table.Indexes[i].Attributes = append(b.migrate.table.Indexes[i].Attributes, *at)
 		}
 	}
 
@@ -296,6 +333,7 @@ func migrateAtt(b body) error {
 			EscapingName: b.driver.KeywordHandler(b.migrate.table.Name + "_idx_" + strings.ToLower(b.migrate.field.Name)),
 			Unique:       true,
 			Attributes:   []AttributeMigrate{*at},
+			Columns:      []IndexColumnMigrate{{}},
 		}
 		b.migrate.table.Indexes = append(b.migrate.table.Indexes, in)
 	}
@@ -306,17 +344,129 @@ func migrateAtt(b body) error {
 			EscapingName: b.driver.KeywordHandler(b.migrate.table.Name + "_idx_" + strings.ToLower(b.migrate.field.Name)),
 			Unique:       false,
 			Attributes:   []AttributeMigrate{*at},
+			Columns:      []IndexColumnMigrate{{}},
+		}
+		b.migrate.table.Indexes = append(b.migrate.table.Indexes, in)
+	}
+	return nil
+}
+
+// migrateIndex adds the attribute to the index declared inside the "index()" tag,
+// indexes with the same name are merged as a multi column index.
+//
+// The index options are separated by spaces:
+//
+//	unique             unique index
+//	n:name             name of the index
+//	desc               sort the attribute in descending order
+//	f:lower            use a function over the attribute
+//	expr:(expression)  use a expression in place of the attribute
+//	include            add the attribute as a non-key column of a covering index
+//	using:gin          index method
+//	where:(predicate)  predicate of a partial index
+func migrateIndex(b body, at *AttributeMigrate, index string) error {
+	options := splitTag(index, ' ')
+
+	indexName := getIndexValue(options, "n:")
+	if indexName == "" {
+		indexName = b.migrate.table.Name + "_idx_" + strings.ToLower(b.migrate.field.Name)
+	}
+	in := IndexMigrate{
+		Name:         b.migrate.table.Name + "_" + indexName,
+		EscapingName: b.driver.KeywordHandler(b.migrate.table.Name + "_" + indexName),
+		Unique:       slices.Contains(options, "unique"),
+		Method:       getIndexValue(options, "using:"),
+		Where:        trimParentheses(getIndexValue(options, "where:")),
+	}
+
+	column := IndexColumnMigrate{
+		Descending: slices.Contains(options, "desc"),
+		Expression: trimParentheses(getIndexValue(options, "expr:")),
+	}
+	if function := getIndexValue(options, "f:"); function != "" && column.Expression == "" {
+		column.Expression = function + "(" + at.EscapingName + ")"
+	}
+	include := slices.Contains(options, "include")
+
+	i := slices.IndexFunc(b.migrate.table.Indexes, func(i IndexMigrate) bool {
+		return i.Name == in.Name
+	})
+	if i == -1 {
+		if include {
+			in.Include = []AttributeMigrate{*at}
+		} else {
+			in.Attributes = []AttributeMigrate{*at}
+			in.Columns = []IndexColumnMigrate{column}
 		}
 		b.migrate.table.Indexes = append(b.migrate.table.Indexes, in)
+		return nil
+	}
+
+	current := &b.migrate.table.Indexes[i]
+	if current.Unique != in.Unique {
+		return fmt.Errorf(`goe: struct "%v" have two or more indexes with same name but different uniqueness "%v"`, b.migrate.table.Name, in.Name)
+	}
+	if current.Method, in.Method = mergeIndexOption(current.Method, in.Method); current.Method != in.Method {
+		return fmt.Errorf(`goe: struct "%v" have two or more indexes with same name but different method "%v"`, b.migrate.table.Name, in.Name)
 	}
+	if current.Where, in.Where = mergeIndexOption(current.Where, in.Where); current.Where != in.Where {
+		return fmt.Errorf(`goe: struct "%v" have two or more indexes with same name but different where "%v"`, b.migrate.table.Name, in.Name)
+	}
+
+	if include {
+		current.Include = append(current.Include, *at)
+		return nil
+	}
+	current.Attributes = append(current.Attributes, *at)
+	current.Columns = append(current.Columns, column)
 	return nil
 }
 
-func getTagType(field reflect.StructField) string {
+// mergeIndexOption fills the empty option with the declared one
+func mergeIndexOption(current, declared string) (string, string) {
+	if current == "" {
+		return declared, declared
+	}
+	if declared == "" {
+		return current, current
+	}
+	return current, declared
+}
+
+// migrateColumnTags sets the nullable, default and check values from the goe tag
+func migrateColumnTags(b body, at *AttributeMigrate) {
+	tagValue := b.migrate.field.Tag.Get("goe")
+	if tagValueExist(tagValue, "null") {
+		at.Nullable = true
+	}
+	if tagValueExist(tagValue, "notnull") {
+		at.Nullable = false
+	}
+	at.Default = getTagValue(tagValue, "default:")
+
+	if check := getTagValue(tagValue, "check:"); check != "" {
+		name := b.migrate.table.Name + "_" + at.Name + "_check"
+		b.migrate.table.Checks = append(b.migrate.table.Checks, CheckMigrate{
+			Name:         name,
+			EscapingName: b.driver.KeywordHandler(name),
+			Expression:   check,
+		})
+	}
+}
+
+func getTagType(field reflect.StructField, driver Driver) string {
 	value := getTagValue(field.Tag.Get("goe"), "type:")
 	if value != "" {
 		return value
 	}
+	if isJSON(field) {
+		return jsonColumn(driver.Name())
+	}
+	if codec := driver.GetDatabaseConfig().getCodec(field.Type); codec != nil {
+		if column, ok := codec.column(driver.Name()); ok {
+			return column
+		}
+	}
 	dataType := field.Type.String()
 	if dataType[0] == '*' {
 		return dataType[1:]
@@ -333,7 +483,7 @@ func getIndex(field reflect.StructField) string {
 }
 
 func tagValueExist(tag string, subTag string) bool {
-	values := strings.Split(tag, ";")
+	values := splitTag(tag, ';')
 	for _, v := range values {
 		if v == subTag {
 			return true
@@ -342,16 +492,64 @@ func tagValueExist(tag string, subTag string) bool {
 	return false
 }
 
-func getIndexValue(valueTag string, tag string) string {
-	values := strings.Split(valueTag, " ")
-	for _, v := range values {
-		if _, value, ok := strings.Cut(v, tag); ok {
+func getIndexValue(options []string, tag string) string {
+	for _, v := range options {
+		if value, ok := strings.CutPrefix(v, tag); ok {
 			return value
 		}
 	}
 	return ""
 }
 
+// splitTag splits the value on sep, ignoring the separators inside parentheses or quotes
+func splitTag(value string, sep rune) []string {
+	values := make([]string, 0)
+	depth, quoted, start := 0, false, 0
+	for i, r := range value {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == sep && depth == 0:
+			if v := strings.TrimSpace(value[start:i]); v != "" {
+				values = append(values, v)
+			}
+			start = i + 1
+		}
+	}
+	if v := strings.TrimSpace(value[start:]); v != "" {
+		values = append(values, v)
+	}
+	return values
+}
+
+// trimParentheses removes the parentheses around the whole value
+func trimParentheses(value string) string {
+	if len(value) < 2 || value[0] != '(' || value[len(value)-1] != ')' {
+		return value
+	}
+	depth, quoted := 0, false
+	for i, r := range value {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		}
+		if depth == 0 && i != len(value)-1 {
+			return value
+		}
+	}
+	return value[1 : len(value)-1]
+}
+
 func createMigratePk(attributeName string, autoIncrement bool, dataType string, driver Driver) *PrimaryKeyMigrate {
 	return &PrimaryKeyMigrate{
 		Name:          utils.ColumnNamePattern(attributeName),
@@ -369,17 +567,49 @@ func createMigrateAtt(attributeName string, dataType string, nullable bool, driv
 	}
 }
 
+var foreignKeyActions = map[string]string{
+	"cascade":    "CASCADE",
+	"restrict":   "RESTRICT",
+	"setnull":    "SET NULL",
+	"setdefault": "SET DEFAULT",
+	"noaction":   "NO ACTION",
+}
+
+// getForeignKeyAction returns the SQL of the referential action from tags like "onDelete:cascade"
+func getForeignKeyAction(b body, subTag string) (string, error) {
+	value := getTagValue(b.migrate.field.Tag.Get("goe"), subTag)
+	if value == "" {
+		return "", nil
+	}
+	action, ok := foreignKeyActions[strings.NewReplacer(" ", "", "_", "").Replace(strings.ToLower(value))]
+	if !ok {
+		return "", fmt.Errorf("goe: struct %q have a invalid %v%v on field %q", b.typeOf.Name(), subTag, value, b.migrate.field.Name)
+	}
+	return action, nil
+}
+
 func helperAttributeMigrate(b body) error {
+	if err := checkForeignKeyTag(b.tables, b.typeOf, b.migrate.field); err != nil {
+		return err
+	}
 	table, prefix := checkTablePattern(b.tables, b.migrate.field)
 	if table != "" {
 		b.stringInfos = stringInfos{prefixName: prefix, tableName: table, fieldName: b.migrate.field.Name}
 		if mto := isManyToOne(b, createManyToOneMigrate, createOneToOneMigrate); mto != nil {
+			var err error
 			switch v := mto.(type) {
 			case *ManyToOneMigrate:
 				if v == nil {
 					return migrateAtt(b)
 				}
-				v.DataType = getTagType(b.migrate.field)
+				v.DataType = getTagType(b.migrate.field, b.driver)
+				migrateColumnTags(b, &v.AttributeMigrate)
+				if v.OnDelete, err = getForeignKeyAction(b, "onDelete:"); err != nil {
+					return err
+				}
+				if v.OnUpdate, err = getForeignKeyAction(b, "onUpdate:"); err != nil {
+					return err
+				}
 				b.migrate.table.ManyToOnes = append(b.migrate.table.ManyToOnes, *v)
 			case *OneToOneMigrate:
 				if v == nil {
@@ -388,7 +618,14 @@ func helperAttributeMigrate(b body) error {
 					}
 					return migrateAtt(b)
 				}
-				v.DataType = getTagType(b.migrate.field)
+				v.DataType = getTagType(b.migrate.field, b.driver)
+				migrateColumnTags(b, &v.AttributeMigrate)
+				if v.OnDelete, err = getForeignKeyAction(b, "onDelete:"); err != nil {
+					return err
+				}
+				if v.OnUpdate, err = getForeignKeyAction(b, "onUpdate:"); err != nil {
+					return err
+				}
 				b.migrate.table.OneToOnes = append(b.migrate.table.OneToOnes, *v)
 			}
 			return nil
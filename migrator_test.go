@@ -0,0 +1,224 @@
+package goe
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type TagProduct struct {
+	Id     int
+	Price  float64 `goe:"check:price > 0"`
+	Status string  `goe:"default:'active'"`
+	Note   string  `goe:"null"`
+	Email  *string `goe:"notnull"`
+	Phone  *string
+}
+
+type TagCode struct {
+	Code string `goe:"pk;default:'none';check:code <> ''"`
+	Name string
+	Kind string `goe:"check:kind <> 'pk'"` // pk inside a tag value is not a primary key
+}
+
+type TagDatabase struct {
+	TagProduct *TagProduct
+	TagCode    *TagCode
+	*DB
+}
+
+func TestMigrateColumnTags(t *testing.T) {
+	db, err := Open[TagDatabase](newFakeDriver("SQLite"))
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	m := migrateFrom(db, db.driver)
+	if m.Error != nil {
+		t.Fatalf("Expected migrator, got error: %v", m.Error)
+	}
+
+	product := m.Tables["tag_products"]
+	attribute := func(name string) AttributeMigrate {
+		i := slices.IndexFunc(product.Attributes, func(a AttributeMigrate) bool { return a.Name == name })
+		if i == -1 {
+			t.Fatalf("Expected attribute %v, got: %v", name, product.Attributes)
+		}
+		return product.Attributes[i]
+	}
+
+	testCases := []struct {
+		desc      string
+		attribute AttributeMigrate
+		nullable  bool
+		value     string
+	}{
+		{desc: "Default", attribute: attribute("status"), nullable: false, value: "'active'"},
+		{desc: "Null", attribute: attribute("note"), nullable: true},
+		{desc: "NotNull", attribute: attribute("email"), nullable: false},
+		{desc: "Pointer", attribute: attribute("phone"), nullable: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.attribute.Nullable != tC.nullable || tC.attribute.Default != tC.value {
+				t.Errorf("Expected nullable %v and default %q, got: %+v", tC.nullable, tC.value, tC.attribute)
+			}
+		})
+	}
+
+	if len(product.Checks) != 1 || product.Checks[0].Name != "tag_products_price_check" || product.Checks[0].Expression != "price > 0" {
+		t.Errorf("Expected check on price, got: %+v", product.Checks)
+	}
+
+	code := m.Tables["tag_codes"]
+	if len(code.PrimaryKeys) != 1 || code.PrimaryKeys[0].Default != "'none'" || code.PrimaryKeys[0].AutoIncrement {
+		t.Errorf("Expected primary key with default, got: %+v", code.PrimaryKeys)
+	}
+	if len(code.Checks) != 2 || code.Checks[0].Expression != "code <> ''" {
+		t.Errorf("Expected check on primary key, got: %+v", code.Checks)
+	}
+}
+
+type NullKey struct {
+	Code string `goe:"pk;null"`
+}
+
+type NullKeyDatabase struct {
+	NullKey *NullKey
+	*DB
+}
+
+type DefaultId struct {
+	Id int `goe:"default:1"`
+}
+
+type DefaultIdDatabase struct {
+	DefaultId *DefaultId
+	*DB
+}
+
+func TestMigratePrimaryKeyTagErrors(t *testing.T) {
+	nullKey, err := Open[NullKeyDatabase](newFakeDriver("SQLite"))
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(nullKey)
+	if m := migrateFrom(nullKey, nullKey.driver); m.Error == nil || !strings.Contains(m.Error.Error(), "null primary key") {
+		t.Errorf("Expected null primary key error, got: %v", m.Error)
+	}
+
+	defaultId, err := Open[DefaultIdDatabase](newFakeDriver("SQLite"))
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(defaultId)
+	if m := migrateFrom(defaultId, defaultId.driver); m.Error == nil || !strings.Contains(m.Error.Error(), "auto increment") {
+		t.Errorf("Expected auto increment default error, got: %v", m.Error)
+	}
+}
+
+func TestSplitTag(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		value string
+		sep   rune
+		want  []string
+	}{
+		{desc: "Options", value: "unique n:idx_email desc", sep: ' ', want: []string{"unique", "n:idx_email", "desc"}},
+		{desc: "ExtraSpaces", value: "  unique   desc ", sep: ' ', want: []string{"unique", "desc"}},
+		{desc: "WhereWithSpaces", value: "n:idx where:(deleted_at IS NULL)", sep: ' ', want: []string{"n:idx", "where:(deleted_at IS NULL)"}},
+		{desc: "NestedParentheses", value: "expr:(lower((name || ' ' || email))) desc", sep: ' ', want: []string{"expr:(lower((name || ' ' || email)))", "desc"}},
+		{desc: "QuotedParentheses", value: "where:(name <> ')' AND age > 1) include", sep: ' ', want: []string{"where:(name <> ')' AND age > 1)", "include"}},
+		{desc: "Indexes", value: "n:idx_a, n:idx_b desc", sep: ',', want: []string{"n:idx_a", "n:idx_b desc"}},
+		{desc: "WhereWithCommas", value: "n:idx_a where:(status IN ('a', 'b')), n:idx_b", sep: ',', want: []string{"n:idx_a where:(status IN ('a', 'b'))", "n:idx_b"}},
+		{desc: "QuotedComma", value: "n:idx_a where:(name = 'a,b')", sep: ',', want: []string{"n:idx_a where:(name = 'a,b')"}},
+		{desc: "QuotedSemicolon", value: "default:'a;b';check:name <> ';'", sep: ';', want: []string{"default:'a;b'", "check:name <> ';'"}},
+		{desc: "Empty", value: "", sep: ',', want: []string{}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if got := splitTag(tC.value, tC.sep); !slices.Equal(got, tC.want) {
+				t.Errorf("Expected %q, got %q", tC.want, got)
+			}
+		})
+	}
+}
+
+func TestTrimParentheses(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  string
+	}{
+		{value: "(deleted_at IS NULL)", want: "deleted_at IS NULL"},
+		{value: "(lower(name))", want: "lower(name)"},
+		{value: "(a > 1) AND (b > 1)", want: "(a > 1) AND (b > 1)"},
+		{value: "(name <> ')')", want: "name <> ')'"},
+		{value: "(name = '(') OR (name = ')')", want: "(name = '(') OR (name = ')')"},
+		{value: "lower(name)", want: "lower(name)"},
+		{value: "()", want: ""},
+		{value: "(", want: "("},
+	}
+	for _, tC := range testCases {
+		if got := trimParentheses(tC.value); got != tC.want {
+			t.Errorf("Expected trimParentheses(%q) %q, got %q", tC.value, tC.want, got)
+		}
+	}
+}
+
+type IndexUser struct {
+	Id        int
+	Email     string `goe:"index(unique n:idx_email where:(deleted_at IS NULL AND status IN ('a', 'b')))"`
+	Name      string `goe:"index(n:idx_name_created expr:(lower((name))), n:idx_name using:hash)"`
+	CreatedAt string `goe:"index(n:idx_name_created desc)"`
+	Status    string `goe:"index(n:idx_name_created include)"`
+	DeletedAt *string
+}
+
+type IndexDatabase struct {
+	IndexUser *IndexUser
+	*DB
+}
+
+func TestMigrateIndexOptions(t *testing.T) {
+	db, err := Open[IndexDatabase](newFakeDriver("SQLite"))
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	m := migrateFrom(db, db.driver)
+	if m.Error != nil {
+		t.Fatalf("Expected migrator, got error: %v", m.Error)
+	}
+
+	indexes := m.Tables["index_users"].Indexes
+	index := func(name string) IndexMigrate {
+		i := slices.IndexFunc(indexes, func(in IndexMigrate) bool { return in.Name == name })
+		if i == -1 {
+			t.Fatalf("Expected index %v, got: %+v", name, indexes)
+		}
+		return indexes[i]
+	}
+
+	email := index("index_users_idx_email")
+	if !email.Unique || email.Where != "deleted_at IS NULL AND status IN ('a', 'b')" {
+		t.Errorf("Expected unique partial index, got: %+v", email)
+	}
+
+	name := index("index_users_idx_name")
+	if name.Method != "hash" || len(name.Attributes) != 1 || name.Attributes[0].Name != "name" {
+		t.Errorf("Expected hash index on name, got: %+v", name)
+	}
+
+	created := index("index_users_idx_name_created")
+	if len(created.Attributes) != 2 || created.Attributes[0].Name != "name" || created.Attributes[1].Name != "created_at" {
+		t.Fatalf("Expected index on name and created_at, got: %+v", created)
+	}
+	if created.Columns[0].Expression != "lower((name))" || created.Columns[0].Descending || !created.Columns[1].Descending {
+		t.Errorf("Expected expression and descending columns, got: %+v", created.Columns)
+	}
+	if len(created.Include) != 1 || created.Include[0].Name != "status" {
+		t.Errorf("Expected include status, got: %+v", created.Include)
+	}
+}
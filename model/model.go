@@ -11,6 +11,7 @@ type Attribute struct {
 	Name          string
 	AggregateType enum.AggregateType
 	FunctionType  enum.FunctionType
+	JSONPath      string // path inside a json attribute, like $.user.id
 }
 
 type JoinArgument struct {
@@ -52,13 +53,16 @@ type Query struct {
 	WhereOperations []Where //Select, Update and Delete
 	WhereIndex      int     //Start of where position arguments $1, $2...
 	Arguments       []any
+	// position of the arguments from sensitive attributes, redacted on logs
+	SensitiveArguments []int
 
 	ReturningId    *Attribute //Insert
 	BatchSizeQuery int        //Insert
 	SizeArguments  int        //Insert
 
-	RawSql string
-	Header QueryHeader
+	RawSql  string
+	Comment string // sql comment with the query tags, appended by the driver to the sql
+	Header  QueryHeader
 }
 
 type QueryHeader struct {
@@ -78,6 +82,8 @@ type Operation struct {
 	Function            enum.FunctionType
 	AttributeValue      string
 	AttributeValueTable string
+	Sensitive           bool
+	JSONPath            string
 }
 
 type Set struct {
@@ -99,6 +105,10 @@ type FunctionType interface {
 	GetType() enum.FunctionType
 }
 
+type JSONPath interface {
+	GetJSONPath() string
+}
+
 type ValueOperation interface {
 	GetValue() any
 }
@@ -31,6 +31,20 @@ func ToLower(target *string) *query.Function[string] {
 	return &query.Function[string]{Field: target, Type: enum.LowerFunction}
 }
 
+// JSONExtract uses database function to get the value on the path of the target json attribute,
+// the path uses the format "$.key.key" or "$.key[0]"
+//
+// # Example
+//
+//	goe.Select(&struct {
+//		UserId *query.JSON[int]
+//	}{
+//		UserId: function.JSONExtract[int](&db.Event.Payload, "$.user.id"),
+//	}).From(db.Event)
+func JSONExtract[T any](target any, path string) *query.JSON[T] {
+	return &query.JSON[T]{Field: target, Path: path}
+}
+
 // Argument is used to pass a value to a function inside a where clause
 //
 // # Example
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 
@@ -31,6 +32,67 @@ func (f Function[T]) GetType() enum.FunctionType {
 	return f.Type
 }
 
+// JSON is the value on the path of a json attribute, created by function.JSONExtract.
+// Numbers, booleans and objects are unmarshaled as json into T.
+type JSON[T any] struct {
+	Field any
+	Path  string
+	Value T
+}
+
+func (j *JSON[T]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		var zero T
+		j.Value = zero
+		return nil
+	case T:
+		j.Value = v
+		return nil
+	case []byte:
+		return j.unmarshal(v)
+	case string:
+		return j.unmarshal([]byte(v))
+	case int64:
+		// booleans are returned as integers by some databases
+		if b, ok := any(&j.Value).(*bool); ok {
+			*b = v != 0
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("error scan json: %w", err)
+	}
+	return j.unmarshal(data)
+}
+
+// unmarshal decodes data into Value, texts are returned without quotes by the databases
+// so a string Value is always the raw text
+func (j *JSON[T]) unmarshal(data []byte) error {
+	if s, ok := any(&j.Value).(*string); ok {
+		*s = string(data)
+		return nil
+	}
+	if err := json.Unmarshal(data, &j.Value); err != nil {
+		return fmt.Errorf("error scan json: %w", err)
+	}
+	return nil
+}
+
+func (j JSON[T]) GetValue() any {
+	return j.Value
+}
+
+func (j JSON[T]) GetType() enum.FunctionType {
+	return enum.JSONExtractFunction
+}
+
+func (j JSON[T]) GetJSONPath() string {
+	return j.Path
+}
+
 type Count struct {
 	Field any
 	Value int64
@@ -142,6 +142,38 @@ func Or() model.Operation {
 	return model.Operation{Operator: enum.Or, Type: enum.LogicalWhere}
 }
 
+// JSONPathEquals compares the value on the path of a json attribute with v,
+// the path uses the format "$.key.key" or "$.key[0]"
+//
+// # Example
+//
+//	// get all events of the user 42
+//	Wheres(where.JSONPathEquals(&db.Event.Payload, "$.user.id", 42))
+func JSONPathEquals[T any, V any](a *T, path string, v V) model.Operation {
+	return model.Operation{Arg: a, Value: valueOperation{value: v}, JSONPath: path, Operator: enum.JSONPathEquals, Type: enum.OperationWhere}
+}
+
+// JSONContains checks if the json attribute contains v, v is marshaled as json
+//
+// # Example
+//
+//	// get all events with the "admin" label
+//	Wheres(where.JSONContains(&db.Event.Labels, []string{"admin"}))
+func JSONContains[T any](a *T, v any) model.Operation {
+	return model.Operation{Arg: a, Value: valueOperation{value: v}, Operator: enum.JSONContains, Type: enum.OperationWhere}
+}
+
+// JSONHasKey checks if the path exists on the json attribute,
+// the path uses the format "$.key.key" or "$.key[0]"
+//
+// # Example
+//
+//	// get all events with a user
+//	Wheres(where.JSONHasKey(&db.Event.Payload, "$.user"))
+func JSONHasKey[T any](a *T, path string) model.Operation {
+	return model.Operation{Arg: a, JSONPath: path, Operator: enum.JSONHasKey, Type: enum.OperationIsWhere}
+}
+
 // # Example
 //
 //	// implicit join using EqualsArg
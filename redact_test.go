@@ -0,0 +1,119 @@
+package goe
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe/query/update"
+	"github.com/go-goe/goe/query/where"
+)
+
+func TestRedactArguments(t *testing.T) {
+	logger := newFakeLogger()
+	tracer := &fakeTracer{}
+	cache := NewMemoryCache(10)
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Logger: logger, IncludeArguments: true, Tracer: tracer, Cache: cache}
+	db, err := Open[LogDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	arguments := func(t *testing.T, e logEntry) []any {
+		t.Helper()
+		args, ok := e.value("arguments")
+		if !ok {
+			t.Fatalf("Expected arguments on log, got %+v", e)
+		}
+		return args.([]any)
+	}
+
+	t.Run("InfoHandler", func(t *testing.T) {
+		_, err := Select(db.LogAnimal).From(db.LogAnimal).Wheres(
+			where.Equals(&db.LogAnimal.Name, "Cat"),
+			where.And(),
+			where.Equals(&db.LogAnimal.Password, "secret"),
+		).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		e := logger.next(t)
+		if args := arguments(t, e); e.level != "info" || !slices.Equal(args, []any{"Cat", "[REDACTED]"}) {
+			t.Errorf("Expected the password redacted, got %v", args)
+		}
+		if args := driver.lastQuery().Arguments; !slices.Equal(args, []any{"Cat", "secret"}) {
+			t.Errorf("Expected the driver to receive the password, got %v", args)
+		}
+	})
+
+	t.Run("InList", func(t *testing.T) {
+		_, err := Select(db.LogAnimal).From(db.LogAnimal).Wheres(
+			where.In(&db.LogAnimal.Password, []string{"a", "b"}),
+			where.And(),
+			where.In(&db.LogAnimal.Id, []int{1, 2}),
+		).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		if args := arguments(t, logger.next(t)); !slices.Equal(args, []any{"[REDACTED]", "[REDACTED]", 1, 2}) {
+			t.Errorf("Expected every value of the password list redacted, got %v", args)
+		}
+	})
+
+	t.Run("ErrorQueryHandler", func(t *testing.T) {
+		driver.err = errors.New("syntax error")
+		defer func() { driver.err = nil }()
+
+		err := Update(db.LogAnimal).Sets(update.Set(&db.LogAnimal.Password, "secret")).Wheres(where.Equals(&db.LogAnimal.Id, 1))
+		if err == nil {
+			t.Fatalf("Expected update error, got nil")
+		}
+		e := logger.next(t)
+		if args := arguments(t, e); e.level != "error" || !slices.Equal(args, []any{"[REDACTED]", 1}) {
+			t.Errorf("Expected the password redacted on error, got %v", args)
+		}
+	})
+
+	t.Run("CustomRedact", func(t *testing.T) {
+		driver.config.Redact = func(v any) any { return fmt.Sprintf("len=%v", len(v.(string))) }
+		driver.rows = [][]any{{1}}
+		defer func() { driver.config.Redact, driver.rows = nil, nil }()
+
+		err := Insert(db.LogAnimal).One(&LogAnimal{Name: "Cat", Password: "secret"})
+		if err != nil {
+			t.Fatalf("Expected insert, got error: %v", err)
+		}
+		if args := arguments(t, logger.next(t)); !slices.Contains(args, "len=6") || slices.Contains(args, "secret") {
+			t.Errorf("Expected the password redacted by Redact, got %v", args)
+		}
+	})
+
+	t.Run("Tracer", func(t *testing.T) {
+		for _, info := range append(slices.Clone(tracer.started), tracer.ended...) {
+			if strings.Contains(fmt.Sprintf("%+v", info), "secret") {
+				t.Errorf("Expected no arguments on the span, got %+v", info)
+			}
+		}
+	})
+
+	t.Run("CacheKey", func(t *testing.T) {
+		_, err := Select(db.LogAnimal).From(db.LogAnimal).Wheres(where.Equals(&db.LogAnimal.Password, "secret")).Cache(time.Minute).AsSlice()
+		if err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		logger.next(t)
+		if cache.Len() != 1 {
+			t.Fatalf("Expected one cached select, got %v", cache.Len())
+		}
+		for key := range cache.items {
+			if strings.Contains(key, "secret") {
+				t.Errorf("Expected the arguments hashed on the cache key, got %v", key)
+			}
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package goe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe/model"
+	"github.com/go-goe/goe/query/where"
+)
+
+// explainDriver is a fake driver that explains the queries with the driver name
+type explainDriver struct {
+	*fakeDriver
+}
+
+func (d explainDriver) ExplainContext(ctx context.Context, query *model.Query) (string, error) {
+	return "plan of " + d.name, nil
+}
+
+type ReplicaAnimal struct {
+	Id   int
+	Name string
+}
+
+type ReplicaDatabase struct {
+	ReplicaAnimal *ReplicaAnimal
+	*DB
+}
+
+func openReplicas(t *testing.T) (*ReplicaDatabase, *fakeDriver, *fakeDriver, *fakeDriver) {
+	t.Helper()
+	primary, first, second := newFakeDriver("primary"), newFakeDriver("first"), newFakeDriver("second")
+	db, err := OpenReplicas[ReplicaDatabase](primary, Replica{Driver: first, Weight: 2}, Replica{Driver: second})
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	t.Cleanup(func() { Close(db) })
+	return db, primary, first, second
+}
+
+func TestReplicaRouting(t *testing.T) {
+	db, primary, first, second := openReplicas(t)
+
+	for range 6 {
+		if _, err := Select(db.ReplicaAnimal).From(db.ReplicaAnimal).AsSlice(); err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+	}
+	if primary.queryCount() != 0 || first.queryCount() != 4 || second.queryCount() != 2 {
+		t.Errorf("Expected selects by weight 0/4/2, got %v/%v/%v", primary.queryCount(), first.queryCount(), second.queryCount())
+	}
+
+	if _, err := Select(db.ReplicaAnimal).From(db.ReplicaAnimal).OnPrimary().AsSlice(); err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	if err := Delete(db.ReplicaAnimal).Wheres(where.Equals(&db.ReplicaAnimal.Id, 1)); err != nil {
+		t.Fatalf("Expected delete, got error: %v", err)
+	}
+	if primary.queryCount() != 2 {
+		t.Errorf("Expected OnPrimary and the delete on primary, got %v queries", primary.queryCount())
+	}
+
+	tx, err := db.NewTransaction()
+	if err != nil {
+		t.Fatalf("Expected transaction, got error: %v", err)
+	}
+	for range 3 {
+		if _, err := Select(db.ReplicaAnimal).From(db.ReplicaAnimal).OnTransaction(tx).AsSlice(); err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+	}
+	if primary.queryCount() != 5 || first.queryCount()+second.queryCount() != 6 {
+		t.Errorf("Expected the transaction pinned to primary, got %v queries on primary", primary.queryCount())
+	}
+}
+
+func TestReplicaPagination(t *testing.T) {
+	primary, first, second := newFakeDriver("primary"), newFakeDriver("first"), newFakeDriver("second")
+	db, err := OpenReplicas[ReplicaDatabase](primary, Replica{Driver: first}, Replica{Driver: second})
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+	for _, d := range []*fakeDriver{primary, first, second} {
+		d.result = func(query *model.Query) [][]any {
+			if len(query.Attributes) == 1 {
+				return [][]any{{int64(2)}}
+			}
+			return [][]any{{1, "Cat"}, {2, "Dog"}}
+		}
+	}
+
+	for range 3 {
+		p, err := Select(db.ReplicaAnimal).From(db.ReplicaAnimal).AsPagination(1, 10)
+		if err != nil {
+			t.Fatalf("Expected pagination, got error: %v", err)
+		}
+		if p.TotalValues != 2 || len(p.Values) != 2 {
+			t.Errorf("Expected two values, got %+v", p)
+		}
+	}
+	// the replicas are picked once by pagination, with a count and a select on each
+	if primary.queryCount() != 0 || first.queryCount() != 4 || second.queryCount() != 2 {
+		t.Errorf("Expected count and page on the same replica, got %v/%v/%v", primary.queryCount(), first.queryCount(), second.queryCount())
+	}
+}
+
+func TestReplicaExplain(t *testing.T) {
+	logger := newFakeLogger()
+	primary := newFakeDriver("primary")
+	primary.config = DatabaseConfig{Logger: logger, QueryThreshold: time.Nanosecond, ExplainSlowQueries: true}
+	replica := explainDriver{newFakeDriver("replica")}
+	db, err := OpenReplicas[ReplicaDatabase](explainDriver{primary}, Replica{Driver: replica})
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	if _, err = Select(db.ReplicaAnimal).From(db.ReplicaAnimal).AsSlice(); err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	logger.next(t)
+	if plan, _ := logger.next(t).value("query_plan"); plan != "plan of replica" {
+		t.Errorf("Expected the plan from the replica, got %v", plan)
+	}
+
+	if _, err = Select(db.ReplicaAnimal).From(db.ReplicaAnimal).OnPrimary().AsSlice(); err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	logger.next(t)
+	if plan, _ := logger.next(t).value("query_plan"); plan != "plan of primary" {
+		t.Errorf("Expected the plan from the primary, got %v", plan)
+	}
+}
+
+func TestReplicaInitError(t *testing.T) {
+	primary, first, second := newFakeDriver("primary"), newFakeDriver("first"), newFakeDriver("second")
+	second.initErr = errors.New("connection refused")
+
+	_, err := OpenReplicas[ReplicaDatabase](primary, Replica{Driver: first}, Replica{Driver: second})
+	if !errors.Is(err, second.initErr) {
+		t.Fatalf("Expected init error, got %v", err)
+	}
+	if !primary.closed || !first.closed {
+		t.Errorf("Expected the primary and the first replica closed, got %v and %v", primary.closed, first.closed)
+	}
+
+	_, err = OpenReplicas[ReplicaDatabase](newFakeDriver("primary"), Replica{})
+	if err == nil {
+		t.Errorf("Expected error of nil replica, got nil")
+	}
+}
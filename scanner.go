@@ -0,0 +1,74 @@
+package goe
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Scanner is a generated scan of a mapped struct, used by goe in place
+// of reflection when selecting the struct; see the "goe scan" command.
+type Scanner[T any] struct {
+	Fields []string       // mapped struct fields, in the order of Dest
+	Dest   func(*T) []any // pointers to the mapped fields of the struct
+}
+
+type scannerEntry struct {
+	scanner any
+	valid   sync.Map // *DB to bool, the mapping of a struct depends on the codecs of the database
+}
+
+var scanners sync.Map
+
+// RegisterScanner registers a generated [Scanner], usually called by
+// the init function of the file generated by "goe scan".
+func RegisterScanner[T any](s Scanner[T]) {
+	scanners.Store(reflect.TypeFor[T](), &scannerEntry{scanner: &s})
+}
+
+// getScanner returns the registered scanner of T if it matches the selected fields,
+// a scanner generated before the struct changes is ignored
+func getScanner[T any](fields []fieldSelect) *Scanner[T] {
+	typeOf := reflect.TypeFor[T]()
+	value, ok := scanners.Load(typeOf)
+	if !ok || len(fields) == 0 {
+		return nil
+	}
+	entry := value.(*scannerEntry)
+	scanner := entry.scanner.(*Scanner[T])
+
+	db := fields[0].getDb()
+	if valid, ok := entry.valid.Load(db); ok {
+		if valid.(bool) {
+			return scanner
+		}
+		return nil
+	}
+
+	valid := validScanner(typeOf, scanner.Fields, fields)
+	entry.valid.Store(db, valid)
+	if !valid {
+		return nil
+	}
+	return scanner
+}
+
+// unregisterScanners removes the checks of the scanners made for the closed database
+func unregisterScanners(db *DB) {
+	scanners.Range(func(_, value any) bool {
+		value.(*scannerEntry).valid.Delete(db)
+		return true
+	})
+}
+
+func validScanner(typeOf reflect.Type, names []string, fields []fieldSelect) bool {
+	if typeOf.Kind() != reflect.Struct || len(names) != len(fields) {
+		return false
+	}
+	for i := range fields {
+		f, ok := fields[i].(field)
+		if !ok || fieldPath(typeOf, f.getFieldId()) != names[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package goe
+
+import (
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+type ScanAnimal struct {
+	Id   int
+	Name string
+}
+
+type ScanFood struct {
+	Id   int
+	Name string
+}
+
+type ScanHabitat struct {
+	Id   int
+	Name string
+}
+
+type ScanDatabase struct {
+	ScanAnimal  *ScanAnimal
+	ScanFood    *ScanFood
+	ScanHabitat *ScanHabitat
+	*DB
+}
+
+func TestScanner(t *testing.T) {
+	var animalScans, foodScans atomic.Int32
+	RegisterScanner(Scanner[ScanAnimal]{
+		Fields: []string{"Id", "Name"},
+		Dest: func(v *ScanAnimal) []any {
+			animalScans.Add(1)
+			return []any{&v.Id, &v.Name}
+		},
+	})
+	// generated before the field Name was added
+	RegisterScanner(Scanner[ScanFood]{
+		Fields: []string{"Id"},
+		Dest: func(v *ScanFood) []any {
+			foodScans.Add(1)
+			return []any{&v.Id}
+		},
+	})
+	defer scanners.Delete(reflect.TypeFor[ScanAnimal]())
+	defer scanners.Delete(reflect.TypeFor[ScanFood]())
+
+	driver := newFakeDriver("SQLite")
+	driver.rows = [][]any{{1, "Cat"}, {2, "Dog"}}
+	db, err := Open[ScanDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+
+	animals, err := Select(db.ScanAnimal).From(db.ScanAnimal).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	if !slices.Equal(animals, []ScanAnimal{{Id: 1, Name: "Cat"}, {Id: 2, Name: "Dog"}}) || animalScans.Load() != 2 {
+		t.Errorf("Expected the scanner to scan two animals, got %v with %v scans", animals, animalScans.Load())
+	}
+
+	foods, err := Select(db.ScanFood).From(db.ScanFood).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	if !slices.Equal(foods, []ScanFood{{Id: 1, Name: "Cat"}, {Id: 2, Name: "Dog"}}) || foodScans.Load() != 0 {
+		t.Errorf("Expected reflection in place of the invalid scanner, got %v with %v scans", foods, foodScans.Load())
+	}
+
+	habitats, err := Select(db.ScanHabitat).From(db.ScanHabitat).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select, got error: %v", err)
+	}
+	if !slices.Equal(habitats, []ScanHabitat{{Id: 1, Name: "Cat"}, {Id: 2, Name: "Dog"}}) {
+		t.Errorf("Expected reflection without scanner, got %v", habitats)
+	}
+
+	value, _ := scanners.Load(reflect.TypeFor[ScanFood]())
+	if valid, ok := value.(*scannerEntry).valid.Load(db.DB); !ok || valid.(bool) {
+		t.Errorf("Expected the invalid scanner to be cached for the database, got %v", valid)
+	}
+	Close(db)
+	if _, ok := value.(*scannerEntry).valid.Load(db.DB); ok {
+		t.Errorf("Expected the scanner check removed on close")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-goe/goe/enum"
 	"github.com/go-goe/goe/model"
@@ -24,6 +25,10 @@ type stateSelect[T any] struct {
 	tables          []any
 	ctx             context.Context
 	anonymousStruct bool
+	onPrimary       bool
+	read            Driver // replica used by the select, if nil one is picked on run
+	cacheTTL        time.Duration
+	fields          map[uintptr]field // fields of the database that owns the table
 	err             error
 }
 
@@ -59,6 +64,18 @@ func (f *find[T]) OnTransaction(tx Transaction) *find[T] {
 	return f
 }
 
+// OnPrimary runs the find on the primary database, see [stateSelect.OnPrimary].
+func (f *find[T]) OnPrimary() *find[T] {
+	f.sSelect.OnPrimary()
+	return f
+}
+
+// Cache uses the cached result for ttl, see [stateSelect.Cache].
+func (f *find[T]) Cache(ttl time.Duration) *find[T] {
+	f.sSelect.Cache(ttl)
+	return f
+}
+
 // Replace the ErrNotFound with err
 func (f *find[T]) OnErrNotFound(err error) *find[T] {
 	f.errNotFound = err
@@ -68,7 +85,7 @@ func (f *find[T]) OnErrNotFound(err error) *find[T] {
 // Finds the record by values on Ids
 func (f *find[T]) ById(value T) (*T, error) {
 	pks, valuesPks, err := getArgsPks(getArgs{
-		addrMap:     addrMap.mapField,
+		addrMap:     f.sSelect.fields,
 		table:       f.table,
 		value:       value,
 		errNotFound: f.errNotFound})
@@ -77,11 +94,7 @@ func (f *find[T]) ById(value T) (*T, error) {
 		return nil, err
 	}
 
-	f.sSelect.Wheres(where.Equals(&pks[0], valuesPks[0]))
-	for i := 1; i < len(pks); i++ {
-		f.sSelect.Wheres(where.And())
-		f.sSelect.Wheres(where.Equals(&pks[i], valuesPks[i]))
-	}
+	f.sSelect.Wheres(equalsOperations(pks, valuesPks)...)
 
 	for row, err := range f.sSelect.Rows() {
 		if err != nil {
@@ -93,12 +106,27 @@ func (f *find[T]) ById(value T) (*T, error) {
 	return nil, f.errNotFound
 }
 
+// ToSQL returns the sql and arguments of [find.ById] without running the query.
+func (f *find[T]) ToSQL(value T) (string, []any, error) {
+	pks, valuesPks, err := getArgsPks(getArgs{
+		addrMap:     f.sSelect.fields,
+		table:       f.table,
+		value:       value,
+		errNotFound: f.errNotFound})
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return f.sSelect.toSQL(equalsOperations(pks, valuesPks)...)
+}
+
 // Finds the record by non-zero values,
 // if returns more than one it's returns the first
 // and ignores the rest
 func (f *find[T]) ByValue(value T) (*T, error) {
 	pks, valuesPks, err := getNonZeroFields(getArgs{
-		addrMap:     addrMap.mapField,
+		addrMap:     f.sSelect.fields,
 		table:       f.table,
 		value:       value,
 		errNotFound: f.errNotFound})
@@ -107,11 +135,7 @@ func (f *find[T]) ByValue(value T) (*T, error) {
 		return nil, err
 	}
 
-	f.sSelect.Wheres(where.Equals(&pks[0], valuesPks[0]))
-	for i := 1; i < len(pks); i++ {
-		f.sSelect.Wheres(where.And())
-		f.sSelect.Wheres(where.Equals(&pks[i], valuesPks[i]))
-	}
+	f.sSelect.Wheres(equalsOperations(pks, valuesPks)...)
 
 	for row, err := range f.sSelect.Rows() {
 		if err != nil {
@@ -173,7 +197,8 @@ func Select[T any](table *T) *stateSelect[T] {
 // See [Select] for examples
 func SelectContext[T any](ctx context.Context, table *T) *stateSelect[T] {
 	var state *stateSelect[T] = createSelectState[T](ctx)
-	argsSelect := getArgsSelect(addrMap.mapField, table)
+	state.fields = fieldsOf(table)
+	argsSelect := getArgsSelect(state.fields, table)
 	if argsSelect.err != nil {
 		state.err = argsSelect.err
 		return state
@@ -189,7 +214,7 @@ func (s *stateSelect[T]) Wheres(brs ...model.Operation) *stateSelect[T] {
 	if s.err != nil {
 		return s
 	}
-	s.err = helperWhere(&s.builder, addrMap.mapField, brs...)
+	s.err = helperWhere(&s.builder, s.fields, brs...)
 	return s
 }
 
@@ -215,7 +240,7 @@ func (s *stateSelect[T]) Skip(i int) *stateSelect[T] {
 
 // OrderByAsc makes a ordained by arg ascending query
 func (s *stateSelect[T]) OrderByAsc(arg any) *stateSelect[T] {
-	field := getArg(arg, addrMap.mapField, nil)
+	field := getArg(arg, s.fields, nil)
 	if field == nil {
 		s.err = errors.New("goe: invalid order by target. try sending a pointer")
 		return s
@@ -226,7 +251,7 @@ func (s *stateSelect[T]) OrderByAsc(arg any) *stateSelect[T] {
 
 // OrderByDesc makes a ordained by arg descending query
 func (s *stateSelect[T]) OrderByDesc(arg any) *stateSelect[T] {
-	field := getArg(arg, addrMap.mapField, nil)
+	field := getArg(arg, s.fields, nil)
 	if field == nil {
 		s.err = errors.New("goe: invalid order by target. try sending a pointer")
 		return s
@@ -244,7 +269,7 @@ func (s *stateSelect[T]) From(tables ...any) *stateSelect[T] {
 	}
 
 	s.builder.tables = make([]int, len(tables))
-	err := getArgsTables(&s.builder, addrMap.mapField, s.builder.tables, tables...)
+	err := getArgsTables(&s.builder, s.fields, s.builder.tables, tables...)
 	if err != nil {
 		s.err = err
 		return s
@@ -261,7 +286,7 @@ func (s *stateSelect[T]) Joins(joins ...model.Joins) *stateSelect[T] {
 	}
 
 	for _, j := range joins {
-		fields, err := getArgsJoin(addrMap.mapField, j.FirstArg(), j.SecondArg())
+		fields, err := getArgsJoin(s.fields, j.FirstArg(), j.SecondArg())
 		if err != nil {
 			s.err = err
 			return s
@@ -292,6 +317,62 @@ func (s *stateSelect[T]) AsQuery() (*model.Query, error) {
 	return &s.builder.query, nil
 }
 
+// ToSQL returns the sql and arguments rendered by the driver, without running the query.
+//
+// # Example
+//
+//	sql, args, err := goe.Select(db.Animal).From(db.Animal).Wheres(where.Equals(&db.Animal.Id, 2)).ToSQL()
+func (s *stateSelect[T]) ToSQL() (string, []any, error) {
+	return s.toSQL()
+}
+
+// toSQL renders a copy of the builder with the extra where operations,
+// keeping the state ready to run
+func (s *stateSelect[T]) toSQL(brs ...model.Operation) (string, []any, error) {
+	if s.err != nil {
+		return "", nil, s.err
+	}
+
+	b := s.copyBuilder()
+	if err := helperWhere(&b, s.fields, brs...); err != nil {
+		return "", nil, err
+	}
+	b.buildSqlSelect()
+
+	return render(b.fieldsSelect[0].getDb().driver, &b.query)
+}
+
+// Explain returns the query plan of the select from the database, without running the query.
+//
+// # Example
+//
+//	plan, err := goe.Select(db.Animal).From(db.Animal).Wheres(where.Equals(&db.Animal.Id, 2)).Explain(ctx)
+func (s *stateSelect[T]) Explain(ctx context.Context) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	b := s.copyBuilder()
+	b.buildSqlSelect()
+
+	driver := b.fieldsSelect[0].getDb().driver
+	explainer, ok := driver.(Explainer)
+	if !ok {
+		return "", unsupported(driver, "Explain")
+	}
+	plan, err := explainer.ExplainContext(ctx, &b.query)
+	if err != nil {
+		return "", driver.GetDatabaseConfig().ErrorHandler(ctx, err)
+	}
+	return plan, nil
+}
+
+// copyBuilder returns a copy of the builder that can be build
+// without changing the state
+func (s *stateSelect[T]) copyBuilder() builder {
+	return s.builder.copy()
+}
+
 type Pagination[T any] struct {
 	TotalValues int64 `json:"total_values"`
 	TotalPages  int   `json:"total_pages"`
@@ -342,6 +423,15 @@ func (s *stateSelect[T]) AsPagination(page, size int) (*Pagination[T], error) {
 	// copy wheres
 	stateCount.builder.brs = s.builder.brs
 
+	// count and select on the same replica, so the total matches the page
+	if s.conn == nil && !s.onPrimary && s.read == nil && len(s.builder.fieldsSelect) != 0 {
+		s.read = s.builder.fieldsSelect[0].getDb().readDriver()
+	}
+	stateCount.conn = s.conn
+	stateCount.onPrimary = s.onPrimary
+	stateCount.read = s.read
+	stateCount.cacheTTL = s.cacheTTL
+
 	var count int64
 	for row, err := range stateCount.Rows() {
 		if err != nil {
@@ -399,6 +489,25 @@ func (s *stateSelect[T]) OnTransaction(tx Transaction) *stateSelect[T] {
 	return s
 }
 
+// OnPrimary runs the select on the primary database instead of a replica,
+// used to read the writes made before the select.
+func (s *stateSelect[T]) OnPrimary() *stateSelect[T] {
+	s.onPrimary = true
+	return s
+}
+
+// Cache stores the result of the select for ttl on the [Cache] of the database config,
+// the result is invalidated by inserts, updates and deletes on the selected tables.
+// Selects inside a transaction don't use the cache.
+//
+// # Example
+//
+//	goe.Select(db.Country).From(db.Country).Cache(time.Hour).AsSlice()
+func (s *stateSelect[T]) Cache(ttl time.Duration) *stateSelect[T] {
+	s.cacheTTL = ttl
+	return s
+}
+
 // Rows return a iterator on rows.
 func (s *stateSelect[T]) Rows() iter.Seq2[T, error] {
 	if s.err != nil {
@@ -410,12 +519,41 @@ func (s *stateSelect[T]) Rows() iter.Seq2[T, error] {
 
 	s.builder.buildSqlSelect()
 
-	driver := s.builder.fieldsSelect[0].getDb().driver
-	if s.conn == nil {
-		s.conn = driver.NewConnection()
+	db := s.builder.fieldsSelect[0].getDb()
+	driver := db.driver
+	read := s.readDriver(db)
+	conn := s.conn
+	if conn == nil {
+		conn = read.NewConnection()
+	}
+
+	var scanner *Scanner[T]
+	if !s.anonymousStruct {
+		scanner = getScanner[T](s.builder.fieldsSelect)
 	}
 
-	return handlerResult[T](s.ctx, s.conn, s.builder.query, len(s.builder.fieldsSelect), s.anonymousStruct, driver.GetDatabaseConfig())
+	dbConfig := db.readConfig(read)
+	if dbConfig.Cache != nil && s.cacheTTL > 0 && s.conn == nil {
+		if key, ok := cacheKey[T](driver, &s.builder.query); ok {
+			return cachedResult(dbConfig, key, cacheTables(&s.builder.query), s.cacheTTL, func() iter.Seq2[T, error] {
+				return handlerResult(s.ctx, conn, s.builder.query, s.builder.fieldsSelect, s.anonymousStruct, scanner, dbConfig)
+			})
+		}
+	}
+
+	return handlerResult(s.ctx, conn, s.builder.query, s.builder.fieldsSelect, s.anonymousStruct, scanner, dbConfig)
+}
+
+// readDriver returns the driver that runs the select, the primary
+// for selects on a transaction or on primary, otherwise a replica
+func (s *stateSelect[T]) readDriver(db *DB) Driver {
+	if s.conn != nil || s.onPrimary {
+		return db.driver
+	}
+	if s.read != nil {
+		return s.read
+	}
+	return db.readDriver()
 }
 
 func createSelectState[T any](ctx context.Context) *stateSelect[T] {
@@ -470,7 +608,7 @@ func (l *list[T]) OrderByDesc(a any) *list[T] {
 
 // Filter creates a where on non-zero values.
 func (l *list[T]) Filter(v T) *list[T] {
-	args, values, err := getNonZeroFields(getArgs{addrMap: addrMap.mapField, table: l.table, value: v})
+	args, values, err := getNonZeroFields(getArgs{addrMap: l.sSelect.fields, table: l.table, value: v})
 	if err != nil {
 		l.err = err
 		return l
@@ -485,6 +623,18 @@ func (l *list[T]) Joins(joins ...model.Joins) *list[T] {
 	return l
 }
 
+// OnPrimary runs the list on the primary database, see [stateSelect.OnPrimary].
+func (l *list[T]) OnPrimary() *list[T] {
+	l.sSelect.OnPrimary()
+	return l
+}
+
+// Cache uses the cached result for ttl, see [stateSelect.Cache].
+func (l *list[T]) Cache(ttl time.Duration) *list[T] {
+	l.sSelect.Cache(ttl)
+	return l
+}
+
 func (l *list[T]) OnTransaction(tx Transaction) *list[T] {
 	l.sSelect.OnTransaction(tx)
 	return l
@@ -509,6 +659,14 @@ func (l *list[T]) AsPagination(page, size int) (*Pagination[T], error) {
 	return l.sSelect.AsPagination(page, size)
 }
 
+// ToSQL returns the sql and arguments of the list, without running the query.
+func (l *list[T]) ToSQL() (string, []any, error) {
+	if l.err != nil {
+		return "", nil, l.err
+	}
+	return l.sSelect.ToSQL()
+}
+
 type getArgs struct {
 	addrMap     map[uintptr]field
 	table       any
@@ -531,13 +689,13 @@ func getArgsPks(a getArgs) ([]any, []any, error) {
 
 	args, values := make([]any, 0, valueOf.NumField()), make([]any, 0, valueOf.NumField())
 	var addr uintptr
-	for i := 0; i < valueOf.NumField(); i++ {
-		if !valueOf.Field(i).IsZero() {
-			addr = uintptr(tableOf.Field(i).Addr().UnsafePointer())
+	for _, field := range structFields(valueOf.Type()) {
+		if !fieldValue(valueOf, field.Index).IsZero() {
+			addr = uintptr(tableOf.FieldByIndex(field.Index).Addr().UnsafePointer())
 			if a.addrMap[addr] != nil {
 				if a.addrMap[addr].isPrimaryKey() {
-					args = append(args, tableOf.Field(i).Addr().Interface())
-					values = append(values, valueOf.Field(i).Interface())
+					args = append(args, tableOf.FieldByIndex(field.Index).Addr().Interface())
+					values = append(values, fieldValue(valueOf, field.Index).Interface())
 				}
 			}
 		}
@@ -559,12 +717,12 @@ func getNonZeroFields(a getArgs) ([]any, []any, error) {
 
 	valueOf := reflect.ValueOf(a.value)
 	var addr uintptr
-	for i := 0; i < valueOf.NumField(); i++ {
-		if !valueOf.Field(i).IsZero() {
-			addr = uintptr(tableOf.Field(i).Addr().UnsafePointer())
+	for _, field := range structFields(valueOf.Type()) {
+		if !fieldValue(valueOf, field.Index).IsZero() {
+			addr = uintptr(tableOf.FieldByIndex(field.Index).Addr().UnsafePointer())
 			if a.addrMap[addr] != nil {
-				args = append(args, tableOf.Field(i).Addr().Interface())
-				values = append(values, valueOf.Field(i).Interface())
+				args = append(args, tableOf.FieldByIndex(field.Index).Addr().Interface())
+				values = append(values, fieldValue(valueOf, field.Index).Interface())
 			}
 		}
 	}
@@ -587,6 +745,17 @@ func helperNonZeroOperation[T any](stateSelect *stateSelect[T], args []any, valu
 	}
 }
 
+// equalsOperations returns the where operations matching all args with the values
+func equalsOperations(args []any, values []any) []model.Operation {
+	brs := make([]model.Operation, 0, len(args)*2-1)
+	brs = append(brs, where.Equals(&args[0], values[0]))
+	for i := 1; i < len(args); i++ {
+		brs = append(brs, where.And())
+		brs = append(brs, where.Equals(&args[i], values[i]))
+	}
+	return brs
+}
+
 func equalsOrLike(f any, a any) model.Operation {
 	v, ok := a.(string)
 
@@ -621,14 +790,16 @@ func getArgsSelect(addrMap map[uintptr]field, arg any) argsSelect {
 	}
 
 	var fieldOf reflect.Value
-	for i := 0; i < valueOf.NumField(); i++ {
-		fieldOf = valueOf.Field(i)
-		if fieldOf.Kind() == reflect.Slice && fieldOf.Type().Elem().Kind() == reflect.Struct {
-			continue
-		}
+	for _, field := range structFields(valueOf.Type()) {
+		fieldOf = valueOf.FieldByIndex(field.Index)
 		addr := uintptr(fieldOf.Addr().UnsafePointer())
 		if addrMap[addr] != nil {
-			fields = append(fields, addrMap[addr])
+			if addrMap[addr].getMode().canSelect() {
+				fields = append(fields, addrMap[addr])
+			}
+			continue
+		}
+		if fieldOf.Kind() == reflect.Slice && fieldOf.Type().Elem().Kind() == reflect.Struct {
 			continue
 		}
 		//get args from anonymous struct
@@ -658,6 +829,20 @@ func getArgsSelectAno(addrMap map[uintptr]field, valueOf reflect.Value) argsSele
 		}
 
 		if fieldOf.Kind() == reflect.Struct {
+			// check if is json extract
+			if j, ok := fieldOf.Interface().(model.JSONPath); ok {
+				if err := checkJSONPath(j.GetJSONPath()); err != nil {
+					return argsSelect{err: err}
+				}
+				if a := getArg(fieldOf.Field(0).Interface(), addrMap, nil); a != nil {
+					if err := jsonTarget(a); err != nil {
+						return argsSelect{err: err}
+					}
+					fields = append(fields, createFunction(a, fieldOf.Interface()))
+					continue
+				}
+				return argsSelect{err: errors.New("goe: invalid argument. try sending a pointer to a database mapped struct as argument")}
+			}
 			// check if is aggregate
 			addr = uintptr(fieldOf.Field(0).Elem().UnsafePointer())
 			if addrMap[addr] != nil {
@@ -682,11 +867,15 @@ func getArgsSelectAno(addrMap map[uintptr]field, valueOf reflect.Value) argsSele
 
 func createFunction(field field, a any) fieldSelect {
 	if f, ok := a.(model.FunctionType); ok {
-		return &functionResult{
+		result := &functionResult{
 			table:         field.table(),
 			db:            field.getDb(),
 			attributeName: field.getAttributeName(),
 			functionType:  f.GetType()}
+		if j, ok := a.(model.JSONPath); ok {
+			result.jsonPath = j.GetJSONPath()
+		}
+		return result
 	}
 
 	return nil
@@ -726,42 +915,51 @@ func getArgsJoin(addrMap map[uintptr]field, args ...any) ([]field, error) {
 }
 
 func getArgsTables(builder *builder, addrMap map[uintptr]field, tables []int, args ...any) error {
-	if reflect.ValueOf(args[0]).Kind() != reflect.Pointer {
+	if len(args) == 0 {
 		return errors.New("goe: invalid table. try sending a pointer to a database mapped struct as table")
 	}
 
-	var ptr uintptr
-	var i int
-
 	builder.query.Tables = make([]string, 0, len(args))
-
-	valueOf := reflect.ValueOf(args[0]).Elem()
-	ptr = uintptr(valueOf.Addr().UnsafePointer())
-	if addrMap[ptr] == nil {
-		return errors.New("goe: invalid table. try sending a pointer to a database mapped struct as table")
-	}
-	tables[i] = addrMap[ptr].getTableId()
-	i++
-	builder.query.Tables = append(builder.query.Tables, addrMap[ptr].table())
-
-	for _, a := range args[1:] {
+	for i, a := range args {
 		if reflect.ValueOf(a).Kind() != reflect.Pointer {
 			return errors.New("goe: invalid table. try sending a pointer to a database mapped struct as table")
 		}
 
-		valueOf = reflect.ValueOf(a).Elem()
-		ptr = uintptr(valueOf.Addr().UnsafePointer())
-		if addrMap[ptr] == nil {
+		f := tableField(reflect.ValueOf(a), addrMap)
+		if f == nil {
 			return errors.New("goe: invalid table. try sending a pointer to a database mapped struct as table")
 		}
-		tables[i] = addrMap[ptr].getTableId()
-		i++
-		builder.query.Tables = append(builder.query.Tables, addrMap[ptr].table())
+		tables[i] = f.getTableId()
+		builder.query.Tables = append(builder.query.Tables, f.table())
 	}
 
 	return nil
 }
 
+// tableField returns the first mapped field of the table pointed by v,
+// the first field can be on a embedded struct pointer
+func tableField(v reflect.Value, addrMap map[uintptr]field) field {
+	if v.IsNil() {
+		return nil
+	}
+	if f := addrMap[uintptr(v.UnsafePointer())]; f != nil {
+		return f
+	}
+	if v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	for _, sf := range structFields(v.Elem().Type()) {
+		fieldOf, err := v.Elem().FieldByIndexErr(sf.Index)
+		if err != nil {
+			continue
+		}
+		if f := addrMap[uintptr(fieldOf.Addr().UnsafePointer())]; f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
 func getArgFunction(arg any, addrMap map[uintptr]field, operation *model.Operation) field {
 	value := reflect.ValueOf(arg)
 	if value.IsNil() {
@@ -813,11 +1011,19 @@ func getAnyArg(value reflect.Value, addrMap map[uintptr]field) field {
 
 func helperWhere(builder *builder, addrMap map[uintptr]field, brs ...model.Operation) error {
 	for _, br := range brs {
+		if p, ok := br.Value.(param); ok && p.err != nil {
+			return p.err
+		}
 		switch br.Type {
 		case enum.OperationWhere:
 			if a := getArg(br.Arg, addrMap, &br); a != nil {
+				if err := jsonOperation(a, &br); err != nil {
+					return err
+				}
 				br.Table = a.table()
 				br.Attribute = a.getAttributeName()
+				br.Sensitive = a.isSensitive()
+				br.Value = columnValue(a, br)
 
 				builder.brs = append(builder.brs, br)
 				continue
@@ -838,6 +1044,7 @@ func helperWhere(builder *builder, addrMap map[uintptr]field, brs ...model.Opera
 			if a := getArg(br.Arg, addrMap, &br); a != nil {
 				br.Table = a.table()
 				br.Attribute = a.getAttributeName()
+				br.Sensitive = a.isSensitive()
 
 				builder.brs = append(builder.brs, br)
 				continue
@@ -845,6 +1052,9 @@ func helperWhere(builder *builder, addrMap map[uintptr]field, brs ...model.Opera
 			return errors.New("goe: invalid where operation. try sending a pointer as parameter")
 		case enum.OperationIsWhere:
 			if a := getArg(br.Arg, addrMap, nil); a != nil {
+				if err := jsonOperation(a, &br); err != nil {
+					return err
+				}
 				br.Table = a.table()
 				br.Attribute = a.getAttributeName()
 
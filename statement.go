@@ -0,0 +1,154 @@
+package goe
+
+import (
+	container "container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// StatementStats are the stats of the prepared statement cache, see [DB.StatementStats].
+type StatementStats struct {
+	Size      int   // statements on cache
+	MaxSize   int   // max of statements on cache, 0 if the cache is disabled
+	Hits      int64 // queries that used a cached statement
+	Misses    int64 // queries that prepared a new statement
+	Evictions int64 // statements closed to respect the max size
+}
+
+// StatementCache is a bounded cache of prepared statements keyed by the rendered sql,
+// the drivers create one by pool when [DatabaseConfig.StatementCacheSize] is set.
+//
+// Inside a transaction the drivers use the pool statement with [sql.Tx.StmtContext].
+type StatementCache struct {
+	mu      sync.Mutex
+	size    int
+	prepare func(context.Context, string) (*sql.Stmt, error)
+	lru     *container.List
+	items   map[string]*container.Element
+	stats   StatementStats
+	closed  bool
+}
+
+type statementEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// NewStatementCache returns a [StatementCache] with up to size statements,
+// prepared with prepare; usually [sql.DB.PrepareContext].
+func NewStatementCache(size int, prepare func(context.Context, string) (*sql.Stmt, error)) *StatementCache {
+	return &StatementCache{
+		size:    max(size, 1),
+		prepare: prepare,
+		lru:     container.New(),
+		items:   make(map[string]*container.Element),
+	}
+}
+
+// Get returns the prepared statement of query, preparing if is not on cache.
+// The release function must be called after the statement is used,
+// a evicted statement is closed after the last release.
+func (c *StatementCache) Get(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	c.mu.Lock()
+	if e, ok := c.items[query]; ok {
+		entry := e.Value.(*statementEntry)
+		entry.refs++
+		c.stats.Hits++
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		return entry.stmt, c.release(entry), nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// other goroutine prepared the same query
+	if e, ok := c.items[query]; ok {
+		stmt.Close()
+		entry := e.Value.(*statementEntry)
+		entry.refs++
+		return entry.stmt, c.release(entry), nil
+	}
+
+	entry := &statementEntry{query: query, stmt: stmt, refs: 1}
+	if c.closed {
+		entry.evicted = true
+		return stmt, c.release(entry), nil
+	}
+	c.items[query] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		c.evict(c.lru.Back())
+	}
+	return stmt, c.release(entry), nil
+}
+
+func (c *StatementCache) release(entry *statementEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			entry.refs--
+			if entry.evicted && entry.refs == 0 {
+				entry.stmt.Close()
+			}
+		})
+	}
+}
+
+func (c *StatementCache) evict(e *container.Element) {
+	entry := c.lru.Remove(e).(*statementEntry)
+	delete(c.items, entry.query)
+	entry.evicted = true
+	c.stats.Evictions++
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// Stats returns the stats of the cache.
+func (c *StatementCache) Stats() StatementStats {
+	if c == nil {
+		return StatementStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	stats.MaxSize = c.size
+	return stats
+}
+
+// Close closes all the cached statements, the statements in use are
+// closed after the last release. The statements prepared after Close are not cached.
+func (c *StatementCache) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	var err error
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*statementEntry)
+		entry.evicted = true
+		if entry.refs == 0 {
+			err = errors.Join(err, entry.stmt.Close())
+		}
+	}
+	c.lru.Init()
+	clear(c.items)
+	return err
+}
@@ -0,0 +1,238 @@
+package goe
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// stmtDriver is a database/sql driver that counts the prepared and closed statements
+type stmtDriver struct {
+	mu       sync.Mutex
+	prepared map[string]int
+	closed   map[string]int
+}
+
+func (d *stmtDriver) Open(string) (sqldriver.Conn, error) { return stmtConn{driver: d}, nil }
+
+func (d *stmtDriver) counts(query string) (prepared, closed int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.prepared[query], d.closed[query]
+}
+
+type stmtConn struct {
+	driver *stmtDriver
+}
+
+func (c stmtConn) Prepare(query string) (sqldriver.Stmt, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.prepared[query]++
+	return stmt{driver: c.driver, query: query}, nil
+}
+func (c stmtConn) Close() error                              { return nil }
+func (c stmtConn) Begin() (sqldriver.Tx, error)              { return nil, errors.ErrUnsupported }
+func (stmtConn) CheckNamedValue(*sqldriver.NamedValue) error { return nil }
+
+type stmt struct {
+	driver *stmtDriver
+	query  string
+}
+
+func (s stmt) Close() error {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+	s.driver.closed[s.query]++
+	return nil
+}
+func (s stmt) NumInput() int                                    { return -1 }
+func (s stmt) Exec([]sqldriver.Value) (sqldriver.Result, error) { return sqldriver.ResultNoRows, nil }
+func (s stmt) Query([]sqldriver.Value) (sqldriver.Rows, error)  { return nil, io.EOF }
+
+func newStatementCache(t *testing.T, size int) (*StatementCache, *stmtDriver) {
+	t.Helper()
+	driver := &stmtDriver{prepared: make(map[string]int), closed: make(map[string]int)}
+	db := sql.OpenDB(stmtConnector{driver: driver})
+	t.Cleanup(func() { db.Close() })
+	return NewStatementCache(size, db.PrepareContext), driver
+}
+
+type stmtConnector struct {
+	driver *stmtDriver
+}
+
+func (c stmtConnector) Connect(context.Context) (sqldriver.Conn, error) { return c.driver.Open("") }
+func (c stmtConnector) Driver() sqldriver.Driver                        { return c.driver }
+
+func TestStatementCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("HitMiss", func(t *testing.T) {
+		cache, driver := newStatementCache(t, 2)
+		for range 3 {
+			s, release, err := cache.Get(ctx, "SELECT 1")
+			if err != nil {
+				t.Fatalf("Expected statement, got error: %v", err)
+			}
+			if s == nil {
+				t.Fatalf("Expected statement, got nil")
+			}
+			release()
+		}
+		if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Size != 1 || stats.MaxSize != 2 {
+			t.Errorf("Expected 2 hits and 1 miss, got %+v", stats)
+		}
+		if prepared, _ := driver.counts("SELECT 1"); prepared != 1 {
+			t.Errorf("Expected the statement prepared once, got %v", prepared)
+		}
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		cache, driver := newStatementCache(t, 2)
+		get := func(query string) func() {
+			t.Helper()
+			_, release, err := cache.Get(ctx, query)
+			if err != nil {
+				t.Fatalf("Expected statement, got error: %v", err)
+			}
+			return release
+		}
+		get("SELECT 1")()
+		get("SELECT 2")()
+		get("SELECT 1")()
+		// SELECT 2 is the least recently used
+		inUse := get("SELECT 3")
+		if _, closed := driver.counts("SELECT 2"); closed != 1 {
+			t.Errorf("Expected the least recently used closed, got %v closes", closed)
+		}
+		if _, closed := driver.counts("SELECT 1"); closed != 0 {
+			t.Errorf("Expected the recently used on cache, got %v closes", closed)
+		}
+		if stats := cache.Stats(); stats.Evictions != 1 || stats.Size != 2 {
+			t.Errorf("Expected one eviction, got %+v", stats)
+		}
+
+		// SELECT 3 is evicted in use
+		get("SELECT 4")()
+		get("SELECT 1")()
+		get("SELECT 5")()
+		if _, closed := driver.counts("SELECT 3"); closed != 0 {
+			t.Errorf("Expected the evicted statement open while in use, got %v closes", closed)
+		}
+		inUse()
+		if _, closed := driver.counts("SELECT 3"); closed != 1 {
+			t.Errorf("Expected the evicted statement closed on release, got %v closes", closed)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		cache, driver := newStatementCache(t, 2)
+		_, release, err := cache.Get(ctx, "SELECT 1")
+		if err != nil {
+			t.Fatalf("Expected statement, got error: %v", err)
+		}
+		_, idle, err := cache.Get(ctx, "SELECT 2")
+		if err != nil {
+			t.Fatalf("Expected statement, got error: %v", err)
+		}
+		idle()
+
+		if err := cache.Close(); err != nil {
+			t.Fatalf("Expected close, got error: %v", err)
+		}
+		if _, closed := driver.counts("SELECT 2"); closed != 1 {
+			t.Errorf("Expected the idle statement closed, got %v closes", closed)
+		}
+		if _, closed := driver.counts("SELECT 1"); closed != 0 {
+			t.Errorf("Expected the statement in use open, got %v closes", closed)
+		}
+		release()
+		release()
+		if _, closed := driver.counts("SELECT 1"); closed != 1 {
+			t.Errorf("Expected the statement closed once on release, got %v closes", closed)
+		}
+
+		_, release, err = cache.Get(ctx, "SELECT 3")
+		if err != nil {
+			t.Fatalf("Expected statement after close, got error: %v", err)
+		}
+		release()
+		if _, closed := driver.counts("SELECT 3"); closed != 1 || cache.Stats().Size != 0 {
+			t.Errorf("Expected the statement not cached after close, got %v closes and %+v", closed, cache.Stats())
+		}
+	})
+}
+
+// statementDriver is a fake driver with a statement cache
+type statementDriver struct {
+	*fakeDriver
+	cache *StatementCache
+}
+
+func (d statementDriver) StatementStats() StatementStats {
+	return d.cache.Stats()
+}
+
+func TestStatementStats(t *testing.T) {
+	db, err := Open[CommentDatabase](newFakeDriver("SQLite"))
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	if stats := db.StatementStats(); stats != (StatementStats{}) {
+		t.Errorf("Expected empty stats without cache, got %+v", stats)
+	}
+	Close(db)
+
+	cache, _ := newStatementCache(t, 5)
+	db, err = Open[CommentDatabase](statementDriver{fakeDriver: newFakeDriver("SQLite"), cache: cache})
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+	for range 2 {
+		_, release, err := cache.Get(context.Background(), "SELECT 1")
+		if err != nil {
+			t.Fatalf("Expected statement, got error: %v", err)
+		}
+		release()
+	}
+	if stats := db.StatementStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 || stats.MaxSize != 5 {
+		t.Errorf("Expected the stats of the driver cache, got %+v", stats)
+	}
+}
+
+func TestStatementStatsReplicas(t *testing.T) {
+	primary, _ := newStatementCache(t, 5)
+	replica, _ := newStatementCache(t, 5)
+	db, err := OpenReplicas[CommentDatabase](
+		statementDriver{fakeDriver: newFakeDriver("primary"), cache: primary},
+		Replica{Driver: statementDriver{fakeDriver: newFakeDriver("replica"), cache: replica}},
+	)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+	for _, cache := range []*StatementCache{primary, replica} {
+		_, release, err := cache.Get(context.Background(), "SELECT 1")
+		if err != nil {
+			t.Fatalf("Expected statement, got error: %v", err)
+		}
+		release()
+	}
+	if stats := db.StatementStats(); stats.Misses != 2 || stats.Size != 2 || stats.MaxSize != 10 {
+		t.Errorf("Expected the stats of the primary and replica caches, got %+v", stats)
+	}
+}
+
+func TestStatementCacheUnsupported(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	driver.config.StatementCacheSize = 10
+	if _, err := Open[CommentDatabase](driver); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected unsupported error, got %v", err)
+	}
+}
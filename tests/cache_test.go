@@ -0,0 +1,37 @@
+package tests_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe"
+)
+
+func TestMemoryCache(t *testing.T) {
+	cache := goe.NewMemoryCache(2)
+
+	cache.Set("animals", []Animal{{Name: "Cat"}}, []string{"animals"}, time.Minute)
+	cache.Set("foods", []Food{{Name: "Meat"}}, []string{"foods", "animal_foods"}, time.Minute)
+
+	if v, ok := cache.Get("animals"); !ok || v.([]Animal)[0].Name != "Cat" {
+		t.Errorf("Expected cached animals, got: %v", v)
+	}
+
+	cache.Set("habitats", []Habitat{{Name: "City"}}, []string{"habitats"}, time.Minute)
+	if _, ok := cache.Get("foods"); ok {
+		t.Errorf("Expected least recently used foods to be removed")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 values on cache, got: %v", cache.Len())
+	}
+
+	cache.InvalidateTables("animals")
+	if _, ok := cache.Get("animals"); ok {
+		t.Errorf("Expected animals to be invalidated")
+	}
+
+	cache.Set("expired", []Animal{}, []string{"animals"}, -time.Second)
+	if _, ok := cache.Get("expired"); ok {
+		t.Errorf("Expected expired value to be removed")
+	}
+}
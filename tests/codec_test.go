@@ -0,0 +1,97 @@
+package tests_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/query/where"
+	"github.com/go-goe/postgres"
+	"github.com/go-goe/sqlite"
+)
+
+type Money struct {
+	Cents int64
+}
+
+type Invoice struct {
+	Id       int
+	Total    Money
+	Discount *Money
+}
+
+type CodecDatabase struct {
+	Invoice *Invoice
+	*goe.DB
+}
+
+func TestCodec(t *testing.T) {
+	config := goe.DatabaseConfig{Codecs: []goe.Codec{goe.NewCodec(map[string]string{"": "bigint"},
+		func(m Money) (any, error) {
+			if m.Cents < 0 {
+				return nil, errors.New("negative money")
+			}
+			return m.Cents, nil
+		},
+		func(src any) (Money, error) {
+			cents, ok := src.(int64)
+			if !ok {
+				return Money{}, fmt.Errorf("invalid money %v", src)
+			}
+			return Money{Cents: cents}, nil
+		},
+	)}}
+
+	var db *CodecDatabase
+	var err error
+	switch os.Getenv("GOE_DRIVER") {
+	case "PostgreSQL":
+		db, err = goe.Open[CodecDatabase](postgres.Open("user=postgres password=postgres host=localhost port=5432 database=postgres", postgres.Config{DatabaseConfig: config}))
+	default:
+		db, err = goe.Open[CodecDatabase](sqlite.Open(filepath.Join(os.TempDir(), "goe_codec.db"), sqlite.Config{DatabaseConfig: config}))
+	}
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+	defer goe.Close(db)
+
+	err = goe.AutoMigrate(db)
+	if err != nil {
+		t.Fatalf("Expected migrate, got error: %v", err)
+	}
+	err = goe.Delete(db.Invoice).Wheres()
+	if err != nil {
+		t.Fatalf("Expected delete invoices, got error: %v", err)
+	}
+
+	invoices := []Invoice{{Total: Money{Cents: 1050}, Discount: &Money{Cents: 50}}, {Total: Money{Cents: 200}}}
+	err = goe.Insert(db.Invoice).All(invoices)
+	if err != nil {
+		t.Fatalf("Expected insert invoices, got error: %v", err)
+	}
+
+	err = goe.Insert(db.Invoice).One(&Invoice{Total: Money{Cents: -1}})
+	if err == nil {
+		t.Errorf("Expected encode error, got nil")
+	}
+
+	result, err := goe.Select(db.Invoice).From(db.Invoice).
+		Wheres(where.Equals(&db.Invoice.Total, Money{Cents: 1050})).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select invoices, got error: %v", err)
+	}
+	if len(result) != 1 || result[0].Total.Cents != 1050 || result[0].Discount == nil || result[0].Discount.Cents != 50 {
+		t.Fatalf("Expected decoded invoice, got: %+v", result)
+	}
+
+	found, err := goe.Find(db.Invoice).ById(Invoice{Id: invoices[1].Id})
+	if err != nil {
+		t.Fatalf("Expected find invoice, got error: %v", err)
+	}
+	if found.Total.Cents != 200 || found.Discount != nil {
+		t.Errorf("Expected invoice with null discount, got: %+v", found)
+	}
+}
@@ -0,0 +1,77 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/query/update"
+	"github.com/go-goe/goe/query/where"
+)
+
+func TestColumnModes(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+
+	err = goe.Delete(db.Customer).Wheres()
+	if err != nil {
+		t.Fatalf("Expected delete customers, got error: %v", err)
+	}
+
+	customer := Customer{Name: "John", Label: "Mr. John", Version: 7, Code: "A1", Secret: "hash"}
+	err = goe.Insert(db.Customer).One(&customer)
+	if err != nil {
+		t.Fatalf("Expected insert customer, got error: %v", err)
+	}
+
+	found, err := goe.Find(db.Customer).ById(Customer{BaseModel: BaseModel{Id: customer.Id}})
+	if err != nil {
+		t.Fatalf("Expected find customer, got error: %v", err)
+	}
+	if found.Label != "" {
+		t.Errorf("Expected ignored label, got: %v", found.Label)
+	}
+	if found.Version != 1 {
+		t.Errorf("Expected read only version with default value 1, got: %v", found.Version)
+	}
+	if found.Code != "A1" {
+		t.Errorf("Expected insert only code A1, got: %v", found.Code)
+	}
+	if found.Secret != "" {
+		t.Errorf("Expected write only secret to not be selected, got: %v", found.Secret)
+	}
+
+	err = goe.Save(db.Customer).ByValue(Customer{BaseModel: BaseModel{Id: customer.Id}, Name: "Jane", Version: 9, Code: "B2", Secret: "new hash"})
+	if err != nil {
+		t.Fatalf("Expected save customer, got error: %v", err)
+	}
+
+	found, err = goe.Find(db.Customer).ById(Customer{BaseModel: BaseModel{Id: customer.Id}})
+	if err != nil {
+		t.Fatalf("Expected find customer, got error: %v", err)
+	}
+	if found.Name != "Jane" || found.Version != 1 || found.Code != "A1" {
+		t.Errorf("Expected only name to be saved, got: %+v", found)
+	}
+
+	err = goe.Update(db.Customer).Sets(update.Set(&db.Customer.Version, 9)).Wheres(where.Equals(&db.Customer.Id, customer.Id))
+	if err == nil {
+		t.Errorf("Expected error on set of read only version, got nil")
+	}
+	err = goe.Update(db.Customer).Sets(update.Set(&db.Customer.Code, "B2")).Wheres(where.Equals(&db.Customer.Id, customer.Id))
+	if err == nil {
+		t.Errorf("Expected error on set of insert only code, got nil")
+	}
+
+	var secret string
+	for row, err := range goe.Select(&struct{ Secret *string }{Secret: &db.Customer.Secret}).From(db.Customer).Rows() {
+		if err != nil {
+			t.Fatalf("Expected select secret, got error: %v", err)
+		}
+		secret = *row.Secret
+	}
+	if secret != "new hash" {
+		t.Errorf("Expected write only secret to be saved, got: %v", secret)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/query/update"
+	"github.com/go-goe/goe/query/where"
 	"github.com/go-goe/postgres"
 	"github.com/go-goe/sqlite"
 	"github.com/google/uuid"
@@ -136,6 +139,44 @@ type Page struct {
 	PageId *int
 }
 
+type BaseModel struct {
+	Id        int
+	CreatedAt time.Time
+}
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type Customer struct {
+	BaseModel
+	Name    string
+	Address Address `goe:"embedded;prefix:addr_"`
+	Label   string  `goe:"-"`
+	Version int     `goe:"readonly;default:1"`
+	Code    string  `goe:"insertonly"`
+	Secret  string  `goe:"writeonly"`
+}
+
+type EventUser struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type EventPayload struct {
+	User EventUser `json:"user"`
+	Tags []string  `json:"tags"`
+}
+
+type Event struct {
+	Id       int
+	Name     string
+	Payload  EventPayload   `goe:"json"`
+	Metadata map[string]any `goe:"json"`
+	Labels   *[]string      `goe:"json"`
+}
+
 type Database struct {
 	Animal         *Animal
 	AnimalFood     *AnimalFood
@@ -154,6 +195,8 @@ type Database struct {
 	Exam           *Exam
 	Select         *Select
 	Page           *Page
+	Customer       *Customer
+	Event          *Event
 	*goe.DB
 }
 
@@ -263,6 +306,173 @@ func TestRace(t *testing.T) {
 	wg.Wait()
 }
 
+func TestRaceQuery(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected setup, got error %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			raceDb, _ := mapDriver[os.Getenv("GOE_DRIVER")]()
+			goe.Close(raceDb)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := goe.Select(db.Animal).From(db.Animal).Wheres(where.Equals(&db.Animal.Name, "Cat")).AsSlice()
+			if err != nil {
+				t.Errorf("Expected select, got error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestToSQL(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+
+	sql, args, err := goe.Select(db.Animal).From(db.Animal).Wheres(where.Equals(&db.Animal.Id, 2)).ToSQL()
+	if err != nil {
+		t.Fatalf("Expected select sql, got error: %v", err)
+	}
+	if !strings.Contains(sql, "SELECT") || !strings.Contains(sql, "animals") || len(args) != 1 || args[0] != 2 {
+		t.Errorf("Expected select sql with one argument, got: %v %v", sql, args)
+	}
+
+	sql, args, err = goe.Insert(db.Animal).ToSQL(&Animal{Name: "Cat"})
+	if err != nil {
+		t.Fatalf("Expected insert sql, got error: %v", err)
+	}
+	if !strings.Contains(sql, "INSERT") || len(args) == 0 {
+		t.Errorf("Expected insert sql with arguments, got: %v %v", sql, args)
+	}
+
+	sql, args, err = goe.Update(db.Animal).Sets(update.Set(&db.Animal.Name, "Cat")).ToSQL(where.Equals(&db.Animal.Id, 2))
+	if err != nil {
+		t.Fatalf("Expected update sql, got error: %v", err)
+	}
+	if !strings.Contains(sql, "UPDATE") || len(args) != 2 {
+		t.Errorf("Expected update sql with two arguments, got: %v %v", sql, args)
+	}
+
+	sql, args, err = goe.Remove(db.Animal).ToSQL(Animal{Id: 2})
+	if err != nil {
+		t.Fatalf("Expected delete sql, got error: %v", err)
+	}
+	if !strings.Contains(sql, "DELETE") || len(args) != 1 {
+		t.Errorf("Expected delete sql with one argument, got: %v %v", sql, args)
+	}
+
+	s := goe.Select(db.Animal).From(db.Animal).Wheres(where.Equals(&db.Animal.Id, 2))
+	first, _, _ := s.ToSQL()
+	second, args, _ := s.ToSQL()
+	if first != second || len(args) != 1 {
+		t.Errorf("Expected ToSQL to keep the builder, got: %v and %v %v", first, second, args)
+	}
+	if _, err = s.AsSlice(); err != nil {
+		t.Errorf("Expected select after ToSQL, got error: %v", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+
+	plan, err := goe.Select(db.Animal).From(db.Animal).Wheres(where.Equals(&db.Animal.Id, 2)).Explain(context.Background())
+	if err != nil {
+		t.Fatalf("Expected query plan, got error: %v", err)
+	}
+	if plan == "" {
+		t.Errorf("Expected a query plan, got empty")
+	}
+}
+
+func TestCompile(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+
+	animals := []Animal{{Name: "Compiled Cat"}, {Name: "Compiled Dog"}}
+	err = goe.Insert(db.Animal).All(animals)
+	if err != nil {
+		t.Fatalf("Expected insert animals, got error: %v", err)
+	}
+
+	findByName, err := goe.Compile(goe.Select(db.Animal).From(db.Animal).
+		Wheres(goe.Param[string]("name", where.Equals, &db.Animal.Name)))
+	if err != nil {
+		t.Fatalf("Expected compiled select, got error: %v", err)
+	}
+
+	for _, a := range animals {
+		result, err := findByName.AsSlice(goe.Params{"name": a.Name})
+		if err != nil {
+			t.Fatalf("Expected select compiled, got error: %v", err)
+		}
+		if len(result) != 1 || result[0].Id != a.Id {
+			t.Errorf("Expected animal %v, got: %v", a.Id, result)
+		}
+	}
+
+	_, err = findByName.AsSlice(goe.Params{})
+	if err == nil {
+		t.Errorf("Expected error on missing param, got nil")
+	}
+
+	_, err = findByName.AsSlice(goe.Params{"name": 1})
+	if err == nil {
+		t.Errorf("Expected error on param of other type, got nil")
+	}
+
+	for _, a := range animals {
+		err = goe.Remove(db.Animal).ById(Animal{Id: a.Id})
+		if err != nil {
+			t.Errorf("Expected remove animal, got error: %v", err)
+		}
+	}
+}
+
+func TestMigratePlan(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected Postgres Connection, got error %v", err)
+	}
+
+	operations, err := goe.MigratePlan(db)
+	if err != nil {
+		t.Fatalf("Expected migrate plan, got error %v", err)
+	}
+
+	if len(operations) != 0 {
+		t.Errorf("Expected no pending operations after migrate, got %v", operations)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected Postgres Connection, got error %v", err)
+	}
+
+	diff, err := goe.Diff(db)
+	if err != nil {
+		t.Fatalf("Expected diff, got error %v", err)
+	}
+
+	if len(diff.MissingTables) != 0 || len(diff.Tables) != 0 {
+		t.Errorf("Expected no schema drift after migrate, got %v", diff)
+	}
+}
+
 func TestMigrate(t *testing.T) {
 	db, err := Setup()
 	if err != nil {
@@ -291,3 +501,72 @@ func TestMigrate(t *testing.T) {
 		t.Fatalf("Expected context.Canceled, got %v", err)
 	}
 }
+
+type Ledger struct {
+	Id   int
+	Name string `goe:"index(n:idx_name)"`
+}
+
+type LedgerDatabase struct {
+	Ledger *Ledger
+	*goe.DB
+}
+
+func TestMigrateOperations(t *testing.T) {
+	var db *LedgerDatabase
+	var err error
+	switch os.Getenv("GOE_DRIVER") {
+	case "PostgreSQL":
+		db, err = goe.Open[LedgerDatabase](postgres.Open("user=postgres password=postgres host=localhost port=5432 database=postgres", postgres.Config{}))
+	default:
+		db, err = goe.Open[LedgerDatabase](sqlite.Open(filepath.Join(os.TempDir(), "goe_ledger.db"), sqlite.Config{}))
+	}
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+	defer goe.Close(db)
+
+	err = goe.AutoMigrate(db)
+	if err != nil {
+		t.Fatalf("Expected migrate, got error: %v", err)
+	}
+
+	testCases := []struct {
+		desc    string
+		operate func() error
+	}{
+		{desc: "AlterColumnType", operate: func() error { return goe.AlterColumnType(db, "Ledger", "Name", "varchar(100)") }},
+		{desc: "SetNullable", operate: func() error { return goe.SetNullable(db, "Ledger", "Name", true) }},
+		{desc: "SetDefault", operate: func() error { return goe.SetDefault(db, "Ledger", "Name", "'ledger'") }},
+		{desc: "DropDefault", operate: func() error { return goe.SetDefault(db, "Ledger", "Name", "") }},
+		{desc: "RenameTable", operate: func() error {
+			if err := goe.RenameTable(db, "Ledger", "OldLedger"); err != nil {
+				return err
+			}
+			return goe.RenameTable(db, "OldLedger", "Ledger")
+		}},
+		{desc: "RenameIndex", operate: func() error {
+			if err := goe.RenameIndex(db, "Ledger", "ledgers_idx_name", "ledgers_idx_old_name"); err != nil {
+				return err
+			}
+			return goe.RenameIndex(db, "Ledger", "ledgers_idx_old_name", "ledgers_idx_name")
+		}},
+		{desc: "DropIndex", operate: func() error { return goe.DropIndex(db, "Ledger", "ledgers_idx_name") }},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tC.operate()
+			if errors.Is(err, errors.ErrUnsupported) {
+				t.Skipf("Skip unsupported operation: %v", err)
+			}
+			if err != nil {
+				t.Errorf("Expected %v, got error: %v", tC.desc, err)
+			}
+		})
+	}
+
+	err = goe.AutoMigrate(db)
+	if err != nil {
+		t.Fatalf("Expected migrate to restore the ledger, got error: %v", err)
+	}
+}
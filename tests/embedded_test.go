@@ -0,0 +1,61 @@
+package tests_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/query/where"
+)
+
+func TestEmbedded(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+
+	err = goe.Delete(db.Customer).Wheres()
+	if err != nil {
+		t.Fatalf("Expected delete customers, got error: %v", err)
+	}
+
+	customer := Customer{
+		BaseModel: BaseModel{CreatedAt: time.Now().UTC().Truncate(time.Second)},
+		Name:      "John",
+		Address:   Address{Street: "Main Street", City: "Springfield"},
+	}
+	err = goe.Insert(db.Customer).One(&customer)
+	if err != nil {
+		t.Fatalf("Expected insert customer, got error: %v", err)
+	}
+	if customer.Id == 0 {
+		t.Fatalf("Expected customer id on embedded field, got zero")
+	}
+
+	customers, err := goe.Select(db.Customer).From(db.Customer).
+		Wheres(where.Equals(&db.Customer.Address.City, "Springfield")).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select customers, got error: %v", err)
+	}
+	if len(customers) != 1 || customers[0].Id != customer.Id || customers[0].Address != customer.Address {
+		t.Fatalf("Expected %+v, got: %+v", customer, customers)
+	}
+
+	err = goe.Save(db.Customer).ByValue(Customer{BaseModel: BaseModel{Id: customer.Id}, Address: Address{City: "Shelbyville"}})
+	if err != nil {
+		t.Fatalf("Expected save customer, got error: %v", err)
+	}
+
+	found, err := goe.Find(db.Customer).ById(Customer{BaseModel: BaseModel{Id: customer.Id}})
+	if err != nil {
+		t.Fatalf("Expected find customer, got error: %v", err)
+	}
+	if found.Address.City != "Shelbyville" || found.Address.Street != "Main Street" || found.Name != "John" {
+		t.Errorf("Expected saved city, got: %+v", found)
+	}
+
+	err = goe.Remove(db.Customer).ById(Customer{BaseModel: BaseModel{Id: customer.Id}})
+	if err != nil {
+		t.Fatalf("Expected remove customer, got error: %v", err)
+	}
+}
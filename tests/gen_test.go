@@ -0,0 +1,120 @@
+package tests_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/gen"
+)
+
+func TestGen(t *testing.T) {
+	schema := &goe.Migrator{Tables: map[string]*goe.TableMigrate{
+		"habitats": {
+			Name:        "habitats",
+			PrimaryKeys: []goe.PrimaryKeyMigrate{{Name: "id", DataType: "uuid"}},
+			Attributes:  []goe.AttributeMigrate{{Name: "name", DataType: "varchar(50)"}},
+		},
+		"animals": {
+			Name:        "animals",
+			PrimaryKeys: []goe.PrimaryKeyMigrate{{Name: "id", DataType: "integer", AutoIncrement: true}},
+			Attributes:  []goe.AttributeMigrate{{Name: "name", DataType: "text"}},
+			ManyToOnes: []goe.ManyToOneMigrate{{
+				AttributeMigrate: goe.AttributeMigrate{Name: "habitat_owner_id", DataType: "uuid", Nullable: true},
+				TargetTable:      "habitats",
+				TargetColumn:     "id",
+				OnDelete:         "CASCADE",
+			}},
+			Indexes: []goe.IndexMigrate{{
+				Name:       "animals_idx_name",
+				Unique:     true,
+				Attributes: []goe.AttributeMigrate{{Name: "name"}},
+				Columns:    []goe.IndexColumnMigrate{{}},
+			}},
+		},
+	}}
+
+	var buf bytes.Buffer
+	err := gen.Generate(&buf, schema, gen.Config{Package: "models"})
+	if err != nil {
+		t.Fatalf("Expected generate, got error: %v", err)
+	}
+
+	_, err = parser.ParseFile(token.NewFileSet(), "models.go", buf.Bytes(), parser.AllErrors)
+	if err != nil {
+		t.Fatalf("Expected valid go code, got error: %v", err)
+	}
+
+	code := buf.String()
+	for _, expected := range []string{
+		"type Animal struct",
+		"type Habitat struct",
+		`HabitatOwnerId *uuid.UUID ` + "`" + `goe:"fk:Habitat.Id;onDelete:cascade"` + "`",
+		`goe:"index(unique n:idx_name)"`,
+		`goe:"type:varchar(50)"`,
+		"Animals []Animal",
+		"Habitat *Habitat",
+		"*goe.DB",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%v", expected, code)
+		}
+	}
+}
+
+func TestScanners(t *testing.T) {
+	dir := t.TempDir()
+	source := `package models
+
+import "github.com/go-goe/goe"
+
+type Animal struct {
+	Id     int
+	Name   string
+	Emoji  *string
+	Foods  []Food
+}
+
+type Food struct {
+	Id   int
+	Name string
+}
+
+type Database struct {
+	Animal *Animal
+	Food   *Food
+	*goe.DB
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(source), 0644); err != nil {
+		t.Fatalf("Expected write models, got error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err := gen.Scanners(&buf, dir)
+	if err != nil {
+		t.Fatalf("Expected generate scanners, got error: %v", err)
+	}
+
+	_, err = parser.ParseFile(token.NewFileSet(), "goe_scan.go", buf.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("Expected valid go code, got error: %v", err)
+	}
+
+	code := buf.String()
+	for _, expected := range []string{
+		"goe.RegisterScanner(goe.Scanner[Animal]{",
+		`Fields: []string{"Id", "Name", "Emoji"},`,
+		"return []any{&v.Id, &v.Name, &v.Emoji}",
+		"goe.RegisterScanner(goe.Scanner[Food]{",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%v", expected, code)
+		}
+	}
+}
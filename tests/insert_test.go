@@ -283,6 +283,31 @@ func TestInsert(t *testing.T) {
 				}
 			},
 		},
+		{
+			desc: "Insert_Unique_Violation",
+			testCase: func(t *testing.T) {
+				u := User{Name: "Unique", Email: "unique@email.com"}
+				err = goe.Insert(db.User).One(&u)
+				if err != nil {
+					t.Fatalf("Expected insert user, got error: %v", err)
+				}
+
+				err = goe.Insert(db.User).One(&User{Name: "Duplicated", Email: u.Email})
+				if !errors.Is(err, goe.ErrUniqueViolation) {
+					t.Fatalf("Expected goe.ErrUniqueViolation, got : %v", err)
+				}
+
+				var databaseError *goe.DatabaseError
+				if !errors.As(err, &databaseError) || databaseError.Err == nil {
+					t.Errorf("Expected a goe.DatabaseError with the native error, got : %v", err)
+				}
+
+				err = goe.Remove(db.User).ById(User{Id: u.Id})
+				if err != nil {
+					t.Errorf("Expected remove user, got error: %v", err)
+				}
+			},
+		},
 		{
 			desc: "Insert_Context_Cancel",
 			testCase: func(t *testing.T) {
@@ -0,0 +1,162 @@
+package tests_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/model"
+	"github.com/go-goe/goe/query"
+	"github.com/go-goe/goe/query/function"
+	"github.com/go-goe/goe/query/where"
+)
+
+func TestJSON(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+
+	err = goe.Delete(db.Event).Wheres()
+	if err != nil {
+		t.Fatalf("Expected delete events, got error: %v", err)
+	}
+
+	labels := []string{"new", "web"}
+	events := []Event{
+		{
+			Name:     "login",
+			Payload:  EventPayload{User: EventUser{Id: 42, Name: "John"}, Tags: []string{"auth"}},
+			Metadata: map[string]any{"ip": "127.0.0.1"},
+			Labels:   &labels,
+		},
+		{
+			Name:    "logout",
+			Payload: EventPayload{User: EventUser{Id: 7, Name: "Jane"}},
+		},
+	}
+	err = goe.Insert(db.Event).All(events)
+	if err != nil {
+		t.Fatalf("Expected insert events, got error: %v", err)
+	}
+
+	login, err := goe.Find(db.Event).ById(Event{Id: events[0].Id})
+	if err != nil {
+		t.Fatalf("Expected find event, got error: %v", err)
+	}
+	if login.Payload.User.Id != 42 || !slices.Equal(login.Payload.Tags, []string{"auth"}) {
+		t.Errorf("Expected decoded payload, got: %+v", login.Payload)
+	}
+	if login.Metadata["ip"] != "127.0.0.1" {
+		t.Errorf("Expected decoded metadata, got: %v", login.Metadata)
+	}
+	if login.Labels == nil || !slices.Equal(*login.Labels, labels) {
+		t.Errorf("Expected decoded labels, got: %v", login.Labels)
+	}
+
+	err = goe.Save(db.Event).ByValue(Event{Id: events[1].Id, Payload: EventPayload{User: EventUser{Id: 8, Name: "Jane"}}})
+	if err != nil {
+		t.Fatalf("Expected save event, got error: %v", err)
+	}
+
+	logouts, err := goe.Select(db.Event).From(db.Event).Wheres(where.Equals(&db.Event.Name, "logout")).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select events, got error: %v", err)
+	}
+	if len(logouts) != 1 || logouts[0].Payload.User.Id != 8 || logouts[0].Labels != nil || logouts[0].Metadata != nil {
+		t.Errorf("Expected saved payload with null labels and metadata, got: %+v", logouts)
+	}
+}
+
+func TestJSONQuery(t *testing.T) {
+	db, err := Setup()
+	if err != nil {
+		t.Fatalf("Expected database, got error: %v", err)
+	}
+
+	err = goe.Delete(db.Event).Wheres()
+	if err != nil {
+		t.Fatalf("Expected delete events, got error: %v", err)
+	}
+
+	labels := []string{"new", "web"}
+	events := []Event{
+		{
+			Name:     "login",
+			Payload:  EventPayload{User: EventUser{Id: 42, Name: "John"}},
+			Metadata: map[string]any{"ip": "127.0.0.1"},
+			Labels:   &labels,
+		},
+		{
+			Name:    "logout",
+			Payload: EventPayload{User: EventUser{Id: 7, Name: "Jane"}},
+		},
+	}
+	err = goe.Insert(db.Event).All(events)
+	if err != nil {
+		t.Fatalf("Expected insert events, got error: %v", err)
+	}
+
+	testCases := []struct {
+		desc      string
+		operation model.Operation
+		names     []string
+	}{
+		{
+			desc:      "JSONPathEquals",
+			operation: where.JSONPathEquals(&db.Event.Payload, "$.user.id", 42),
+			names:     []string{"login"},
+		},
+		{
+			desc:      "JSONPathEquals_String",
+			operation: where.JSONPathEquals(&db.Event.Payload, "$.user.name", "Jane"),
+			names:     []string{"logout"},
+		},
+		{
+			desc:      "JSONContains",
+			operation: where.JSONContains(&db.Event.Labels, []string{"web"}),
+			names:     []string{"login"},
+		},
+		{
+			desc:      "JSONHasKey",
+			operation: where.JSONHasKey(&db.Event.Metadata, "$.ip"),
+			names:     []string{"login"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			rows, err := goe.Select(db.Event).From(db.Event).Wheres(tC.operation).OrderByAsc(&db.Event.Id).AsSlice()
+			if err != nil {
+				t.Fatalf("Expected select events, got error: %v", err)
+			}
+			names := make([]string, len(rows))
+			for i := range rows {
+				names[i] = rows[i].Name
+			}
+			if !slices.Equal(names, tC.names) {
+				t.Errorf("Expected %v, got: %v", tC.names, names)
+			}
+		})
+	}
+
+	rows, err := goe.Select(&struct {
+		Name     *string
+		UserId   *query.JSON[int]
+		UserName *query.JSON[string]
+	}{
+		Name:     &db.Event.Name,
+		UserId:   function.JSONExtract[int](&db.Event.Payload, "$.user.id"),
+		UserName: function.JSONExtract[string](&db.Event.Payload, "$.user.name"),
+	}).From(db.Event).OrderByAsc(&db.Event.Id).AsSlice()
+	if err != nil {
+		t.Fatalf("Expected select json extract, got error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].UserId.Value != 42 || rows[0].UserName.Value != "John" || rows[1].UserId.Value != 7 {
+		t.Errorf("Expected extracted users, got: %+v", rows)
+	}
+
+	_, err = goe.Select(db.Event).From(db.Event).Wheres(where.JSONHasKey(&db.Event.Payload, "$.user'; --")).AsSlice()
+	if err == nil {
+		t.Errorf("Expected invalid json path error, got nil")
+	}
+}
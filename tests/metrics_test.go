@@ -0,0 +1,45 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe"
+	"github.com/go-goe/goe/enum"
+)
+
+func TestMemoryMetrics(t *testing.T) {
+	metrics := goe.NewMemoryMetrics()
+	metrics.Collect(goe.QueryMetric{Database: "PostgreSQL", Type: enum.SelectQuery, Table: "animals", Duration: 2 * time.Millisecond, Rows: 3})
+	metrics.Collect(goe.QueryMetric{Database: "PostgreSQL", Type: enum.SelectQuery, Table: "animals", Duration: 20 * time.Millisecond, Rows: 2})
+	metrics.Collect(goe.QueryMetric{Database: "PostgreSQL", Type: enum.InsertQuery, Table: "foods", Duration: time.Millisecond, Rows: -1, ErrorClass: goe.ErrorClass(context.Canceled)})
+
+	var buf bytes.Buffer
+	if err := metrics.WritePrometheus(&buf); err != nil {
+		t.Fatalf("Expected write metrics, got error: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		`goe_queries_total{database="PostgreSQL",operation="select",table="animals",error=""} 2`,
+		`goe_queries_total{database="PostgreSQL",operation="insert",table="foods",error="canceled"} 1`,
+		`goe_query_rows_total{database="PostgreSQL",operation="select",table="animals",error=""} 5`,
+		`goe_query_duration_seconds_bucket{database="PostgreSQL",operation="select",table="animals",error="",le="0.005"} 1`,
+		`goe_query_duration_seconds_bucket{database="PostgreSQL",operation="select",table="animals",error="",le="0.025"} 2`,
+		`goe_query_duration_seconds_count{database="PostgreSQL",operation="select",table="animals",error=""} 2`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected metrics to contain %q, got:\n%v", expected, output)
+		}
+	}
+
+	metrics.Reset()
+	buf.Reset()
+	metrics.WritePrometheus(&buf)
+	if strings.Contains(buf.String(), "animals") {
+		t.Errorf("Expected empty metrics after reset, got:\n%v", buf.String())
+	}
+}
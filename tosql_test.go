@@ -0,0 +1,101 @@
+package goe
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-goe/goe/query/update"
+	"github.com/go-goe/goe/query/where"
+)
+
+type SqlAnimal struct {
+	Id   int
+	Name string
+}
+
+type SqlDatabase struct {
+	SqlAnimal *SqlAnimal
+	*DB
+}
+
+func TestToSQL(t *testing.T) {
+	driver := newFakeDriver("SQLite")
+	driver.rows = [][]any{{1, "Cat"}}
+	db, err := Open[SqlDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	t.Run("Update", func(t *testing.T) {
+		u := Update(db.SqlAnimal).Sets(update.Set(&db.SqlAnimal.Name, "Dog"))
+		for range 2 {
+			_, args, err := u.ToSQL(where.Equals(&db.SqlAnimal.Id, 1))
+			if err != nil {
+				t.Fatalf("Expected update sql, got error: %v", err)
+			}
+			if !slices.Equal(args, []any{"Dog", 1}) {
+				t.Errorf("Expected arguments [Dog 1], got %v", args)
+			}
+		}
+
+		if err = u.Wheres(where.Equals(&db.SqlAnimal.Id, 2)); err != nil {
+			t.Fatalf("Expected update, got error: %v", err)
+		}
+		if args := driver.lastQuery().Arguments; !slices.Equal(args, []any{"Dog", 2}) {
+			t.Errorf("Expected update arguments [Dog 2] after ToSQL, got %v", args)
+		}
+	})
+
+	t.Run("Save", func(t *testing.T) {
+		s := Save(db.SqlAnimal)
+		_, args, err := s.ToSQL(SqlAnimal{Id: 1, Name: "Dog"})
+		if err != nil {
+			t.Fatalf("Expected save sql, got error: %v", err)
+		}
+		if !slices.Equal(args, []any{"Dog", 1}) {
+			t.Errorf("Expected arguments [Dog 1], got %v", args)
+		}
+		if len(s.update.builder.sets) != 0 || len(s.update.builder.brs) != 0 {
+			t.Errorf("Expected ToSQL to keep the save builder, got sets %v and wheres %v", s.update.builder.sets, s.update.builder.brs)
+		}
+
+		if err = s.ByValue(SqlAnimal{Id: 1, Name: "Bird"}); err != nil {
+			t.Fatalf("Expected save, got error: %v", err)
+		}
+		if args := driver.lastQuery().Arguments; !slices.Equal(args, []any{"Bird", 1}) {
+			t.Errorf("Expected save arguments [Bird 1] after ToSQL, got %v", args)
+		}
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		s := Select(db.SqlAnimal).From(db.SqlAnimal).Wheres(where.Equals(&db.SqlAnimal.Id, 1))
+		first, _, err := s.ToSQL()
+		if err != nil {
+			t.Fatalf("Expected select sql, got error: %v", err)
+		}
+		second, args, _ := s.ToSQL()
+		if first != second || !slices.Equal(args, []any{1}) {
+			t.Errorf("Expected the same select sql, got %q and %q with %v", first, second, args)
+		}
+		if _, err = s.AsSlice(); err != nil {
+			t.Fatalf("Expected select, got error: %v", err)
+		}
+		if args := driver.lastQuery().Arguments; !slices.Equal(args, []any{1}) {
+			t.Errorf("Expected select arguments [1] after ToSQL, got %v", args)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		d := Delete(db.SqlAnimal)
+		if _, _, err := d.ToSQL(where.Equals(&db.SqlAnimal.Id, 1)); err != nil {
+			t.Fatalf("Expected delete sql, got error: %v", err)
+		}
+		if err := d.Wheres(where.Equals(&db.SqlAnimal.Id, 2)); err != nil {
+			t.Fatalf("Expected delete, got error: %v", err)
+		}
+		if query := driver.lastQuery(); !slices.Equal(query.Arguments, []any{2}) || len(query.WhereOperations) != 1 {
+			t.Errorf("Expected one where after ToSQL, got %v", query.WhereOperations)
+		}
+	})
+}
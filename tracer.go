@@ -0,0 +1,171 @@
+package goe
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-goe/goe/enum"
+	"github.com/go-goe/goe/model"
+)
+
+// Tracer receives a span around every query runned by goe,
+// it's set on [DatabaseConfig] and can be used as a adapter for OpenTelemetry.
+type Tracer interface {
+	// StartQuery is called before the query runs, the returned context is used to run the query.
+	StartQuery(ctx context.Context, query QueryInfo) context.Context
+	// EndQuery is called with the context returned by StartQuery after the query ends,
+	// for select queries after the rows are closed.
+	EndQuery(ctx context.Context, query QueryInfo)
+}
+
+// QueryInfo describes a query to a [Tracer].
+//
+// On StartQuery the Sql is only filled for raw queries, because the driver
+// renders the sql when the query runs; the QueryDuration is filled on EndQuery,
+// for select queries it includes the scan of the rows until they are closed.
+type QueryInfo struct {
+	Database  string
+	Type      enum.QueryType
+	Tables    []string
+	Sql       string
+	Arguments int   // number of arguments used on query
+	Rows      int64 // rows returned by the query, -1 if unknown
+	Header    model.QueryHeader
+	Err       error
+}
+
+type querySpan struct {
+	tracer   Tracer
+	metrics  MetricsCollector
+	classify func(error) error
+	ctx      context.Context
+	query    *model.Query
+	info     QueryInfo
+	start    time.Time
+	ended    bool
+}
+
+// startQuery starts a span if the config has a [Tracer] or a [MetricsCollector],
+// returns a nil span otherwise
+func (c *DatabaseConfig) startQuery(ctx context.Context, query *model.Query) (context.Context, *querySpan) {
+	if c.Tracer == nil && c.Metrics == nil {
+		return ctx, nil
+	}
+
+	span := &querySpan{tracer: c.Tracer, metrics: c.Metrics, classify: c.classifyError, ctx: ctx, query: query, info: QueryInfo{
+		Database:  c.databaseName,
+		Type:      query.Type,
+		Tables:    query.Tables,
+		Sql:       query.RawSql,
+		Arguments: len(query.Arguments),
+		Rows:      -1,
+		Header:    query.Header,
+	}}
+	if c.Tracer != nil {
+		span.ctx = c.Tracer.StartQuery(ctx, span.info)
+	}
+	span.start = time.Now()
+	return span.ctx, span
+}
+
+func (s *querySpan) end(rows int64, err error) {
+	if s == nil || s.ended {
+		return
+	}
+	s.ended = true
+
+	s.info.Sql = s.query.RawSql
+	s.info.Header = s.query.Header
+	// includes the scan of the rows
+	s.info.Header.QueryDuration = time.Since(s.start)
+	s.info.Rows = rows
+	s.info.Err = s.classify(err)
+
+	if s.metrics != nil {
+		var table string
+		if len(s.info.Tables) != 0 {
+			table = unquote(s.info.Tables[0])
+		}
+		s.metrics.Collect(QueryMetric{
+			Database:   s.info.Database,
+			Type:       s.info.Type,
+			Table:      table,
+			Duration:   s.info.Header.QueryDuration,
+			Rows:       rows,
+			ErrorClass: ErrorClass(s.info.Err),
+		})
+	}
+	if s.tracer != nil {
+		s.tracer.EndQuery(s.ctx, s.info)
+	}
+}
+
+// tracedRows counts the returned rows and ends the span when the rows
+// are done or closed, with the first error of the scans or the iteration
+type tracedRows struct {
+	Rows
+	span  *querySpan
+	count int64
+	err   error
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *tracedRows) Scan(dest ...any) error {
+	err := r.Rows.Scan(dest...)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return err
+}
+
+// Err returns the error of the iteration, if the driver rows report it
+func (r *tracedRows) Err() error {
+	err := rowsErr(r.Rows)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return err
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.end()
+	return err
+}
+
+func (r *tracedRows) end() {
+	r.Err()
+	r.span.end(r.count, r.err)
+}
+
+// rowsErr returns the error of the iteration of rows that have a Err method, like [sql.Rows]
+func rowsErr(rows Rows) error {
+	if errRows, ok := rows.(interface{ Err() error }); ok {
+		return errRows.Err()
+	}
+	return nil
+}
+
+// tracedRow ends the span on scan, where the query error is returned
+type tracedRow struct {
+	Row
+	span *querySpan
+}
+
+func (r tracedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	if err != nil {
+		r.span.end(0, err)
+		return err
+	}
+	r.span.end(1, nil)
+	return nil
+}
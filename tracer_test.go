@@ -0,0 +1,161 @@
+package goe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-goe/goe/enum"
+	"github.com/go-goe/goe/query/where"
+)
+
+type spanKey struct{}
+
+// fakeTracer records the spans, the started spans are marked on the context
+type fakeTracer struct {
+	mu      sync.Mutex
+	started []QueryInfo
+	ended   []QueryInfo
+	marked  int // ended spans with the context from StartQuery
+}
+
+func (t *fakeTracer) StartQuery(ctx context.Context, query QueryInfo) context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started = append(t.started, query)
+	return context.WithValue(ctx, spanKey{}, len(t.started))
+}
+
+func (t *fakeTracer) EndQuery(ctx context.Context, query QueryInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ended = append(t.ended, query)
+	if ctx.Value(spanKey{}) == len(t.started) {
+		t.marked++
+	}
+}
+
+func (t *fakeTracer) last() QueryInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ended[len(t.ended)-1]
+}
+
+func (t *fakeTracer) counts() (started, ended int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.started), len(t.ended)
+}
+
+type TraceAnimal struct {
+	Id   int
+	Name string
+}
+
+type TraceDatabase struct {
+	TraceAnimal *TraceAnimal
+	*DB
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &fakeTracer{}
+	driver := newFakeDriver("SQLite")
+	driver.config = DatabaseConfig{Tracer: tracer}
+	db, err := Open[TraceDatabase](driver)
+	if err != nil {
+		t.Fatalf("Expected open, got error: %v", err)
+	}
+	defer Close(db)
+
+	t.Run("Rows", func(t *testing.T) {
+		driver.rows = [][]any{{1, "Cat"}, {2, "Dog"}}
+		defer func() { driver.rows = nil }()
+
+		var started, ended int
+		for _, err := range Select(db.TraceAnimal).From(db.TraceAnimal).Rows() {
+			if err != nil {
+				t.Fatalf("Expected row, got error: %v", err)
+			}
+			started, ended = tracer.counts()
+			time.Sleep(time.Millisecond)
+		}
+		if started-ended != 1 {
+			t.Errorf("Expected the span open during the scan, got %v started and %v ended", started, ended)
+		}
+
+		info := tracer.last()
+		if info.Type != enum.SelectQuery || info.Rows != 2 || info.Err != nil || info.Header.QueryDuration < 2*time.Millisecond {
+			t.Errorf("Expected select span with two rows and the scan duration, got %+v", info)
+		}
+	})
+
+	t.Run("ScanError", func(t *testing.T) {
+		driver.rows = [][]any{{"one", "Cat"}}
+		defer func() { driver.rows = nil }()
+
+		_, err := Select(db.TraceAnimal).From(db.TraceAnimal).AsSlice()
+		if err == nil {
+			t.Fatalf("Expected scan error, got nil")
+		}
+		if info := tracer.last(); info.Err == nil || info.Rows != 1 {
+			t.Errorf("Expected span with the scan error, got %+v", info)
+		}
+	})
+
+	t.Run("RowsError", func(t *testing.T) {
+		driver.rows = [][]any{{1, "Cat"}}
+		driver.rowsErr = errors.New("connection reset")
+		defer func() { driver.rows, driver.rowsErr = nil, nil }()
+
+		_, err := Select(db.TraceAnimal).From(db.TraceAnimal).AsSlice()
+		if !errors.Is(err, driver.rowsErr) {
+			t.Fatalf("Expected rows error, got %v", err)
+		}
+		if info := tracer.last(); !errors.Is(info.Err, driver.rowsErr) {
+			t.Errorf("Expected span with the rows error, got %+v", info)
+		}
+	})
+
+	t.Run("QueryError", func(t *testing.T) {
+		driver.err = errors.New("syntax error")
+		defer func() { driver.err = nil }()
+
+		_, err := Select(db.TraceAnimal).From(db.TraceAnimal).AsSlice()
+		if !errors.Is(err, driver.err) {
+			t.Fatalf("Expected query error, got %v", err)
+		}
+		if info := tracer.last(); !errors.Is(info.Err, driver.err) || info.Rows != -1 {
+			t.Errorf("Expected span with the query error, got %+v", info)
+		}
+	})
+
+	t.Run("Exec", func(t *testing.T) {
+		err := Delete(db.TraceAnimal).Wheres(where.Equals(&db.TraceAnimal.Id, 1))
+		if err != nil {
+			t.Fatalf("Expected delete, got error: %v", err)
+		}
+		if info := tracer.last(); info.Type != enum.DeleteQuery || info.Err != nil || len(info.Tables) != 1 {
+			t.Errorf("Expected delete span, got %+v", info)
+		}
+	})
+
+	t.Run("Row", func(t *testing.T) {
+		driver.rows = [][]any{{1, "Cat"}}
+		defer func() { driver.rows = nil }()
+
+		_, err := Find(db.TraceAnimal).ById(TraceAnimal{Id: 1})
+		if err != nil {
+			t.Fatalf("Expected find, got error: %v", err)
+		}
+		if info := tracer.last(); info.Type != enum.SelectQuery || info.Err != nil {
+			t.Errorf("Expected find span, got %+v", info)
+		}
+	})
+
+	started, ended := tracer.counts()
+	if started != ended || tracer.marked != ended {
+		t.Errorf("Expected every span ended once with the started context, got %v started, %v ended and %v with context", started, ended, tracer.marked)
+	}
+}
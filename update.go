@@ -3,11 +3,11 @@ package goe
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/go-goe/goe/enum"
 	"github.com/go-goe/goe/model"
-	"github.com/go-goe/goe/query/where"
 )
 
 type save[T any] struct {
@@ -59,24 +59,34 @@ func (s *save[T]) ByValue(v T) error {
 		return s.update.err
 	}
 
-	if _, err := Find(s.table).OnErrNotFound(s.errNotFound).OnTransaction(s.tx).ById(v); err != nil {
+	if _, err := Find(s.table).OnPrimary().OnErrNotFound(s.errNotFound).OnTransaction(s.tx).ById(v); err != nil {
 		return err
 	}
 
-	argsSave := getArgsSave(addrMap.mapField, s.table, v)
+	argsSave := getArgsSave(s.update.fields, s.table, v)
 	if argsSave.err != nil {
 		return argsSave.err
 	}
 
-	wheres := make([]model.Operation, 0, len(argsSave.argsWhere))
-	wheres = append(wheres, where.Equals(&argsSave.argsWhere[0], argsSave.valuesWhere[0]))
-	for i := 1; i < len(argsSave.argsWhere); i++ {
-		wheres = append(wheres, where.And())
-		wheres = append(wheres, where.Equals(&argsSave.argsWhere[i], argsSave.valuesWhere[i]))
+	s.update.builder.sets = argsSave.sets
+	return s.update.Wheres(equalsOperations(argsSave.argsWhere, argsSave.valuesWhere)...)
+}
+
+// ToSQL returns the sql and arguments of [save.ByValue] without running the query.
+func (s *save[T]) ToSQL(v T) (string, []any, error) {
+	if s.update.err != nil {
+		return "", nil, s.update.err
 	}
 
-	s.update.builder.sets = argsSave.sets
-	return s.update.Wheres(wheres...)
+	argsSave := getArgsSave(s.update.fields, s.table, v)
+	if argsSave.err != nil {
+		return "", nil, argsSave.err
+	}
+
+	update := *s.update
+	update.builder = s.update.builder.copy()
+	update.builder.sets = argsSave.sets
+	return update.ToSQL(equalsOperations(argsSave.argsWhere, argsSave.valuesWhere)...)
 }
 
 func (s *save[T]) AndFindByValue(v T) (*T, error) {
@@ -84,7 +94,7 @@ func (s *save[T]) AndFindByValue(v T) (*T, error) {
 	if err != nil {
 		return nil, err
 	}
-	return Find(s.table).OnErrNotFound(s.errNotFound).OnTransaction(s.tx).ById(v)
+	return Find(s.table).OnPrimary().OnErrNotFound(s.errNotFound).OnTransaction(s.tx).ById(v)
 }
 
 func (s *save[T]) OrCreateByValue(v T) (*T, error) {
@@ -95,13 +105,14 @@ func (s *save[T]) OrCreateByValue(v T) (*T, error) {
 		}
 		return nil, err
 	}
-	return Find(s.table).OnErrNotFound(s.errNotFound).OnTransaction(s.tx).ById(v)
+	return Find(s.table).OnPrimary().OnErrNotFound(s.errNotFound).OnTransaction(s.tx).ById(v)
 }
 
 type stateUpdate[T any] struct {
 	conn    Connection
 	builder builder
 	ctx     context.Context
+	fields  map[uintptr]field // fields of the database that owns the table
 	err     error
 }
 
@@ -131,7 +142,12 @@ func Update[T any](table *T) *stateUpdate[T] {
 //
 // See [Update] for examples
 func UpdateContext[T any](ctx context.Context, table *T) *stateUpdate[T] {
-	return createUpdateState[T](ctx)
+	state := createUpdateState[T](ctx)
+	state.fields = fieldsOf(table)
+	if state.fields == nil {
+		state.err = errors.New("goe: invalid argument. try sending a pointer to a database mapped struct as argument")
+	}
+	return state
 }
 
 // Sets one or more arguments for update
@@ -141,7 +157,11 @@ func (s *stateUpdate[T]) Sets(sets ...model.Set) *stateUpdate[T] {
 	}
 
 	for i := range sets {
-		if field := getArg(sets[i].Attribute, addrMap.mapField, nil); field != nil {
+		if field := getArg(sets[i].Attribute, s.fields, nil); field != nil {
+			if !field.getMode().canUpdate() {
+				s.err = fmt.Errorf("goe: invalid set, the column %v is readonly or insertonly", field.getAttributeName())
+				return s
+			}
 			s.builder.sets = append(s.builder.sets, set{attribute: field, value: sets[i].Value})
 		}
 	}
@@ -159,7 +179,7 @@ func (s *stateUpdate[T]) Wheres(brs ...model.Operation) error {
 	if s.err != nil {
 		return s.err
 	}
-	s.err = helperWhere(&s.builder, addrMap.mapField, brs...)
+	s.err = helperWhere(&s.builder, s.fields, brs...)
 	if s.err != nil {
 		return s.err
 	}
@@ -174,6 +194,25 @@ func (s *stateUpdate[T]) Wheres(brs ...model.Operation) error {
 	return handlerValues(s.ctx, s.conn, s.builder.query, driver.GetDatabaseConfig())
 }
 
+// ToSQL returns the sql and arguments of the update with the where operations, without running the query.
+func (s *stateUpdate[T]) ToSQL(brs ...model.Operation) (string, []any, error) {
+	if s.err != nil {
+		return "", nil, s.err
+	}
+
+	if len(s.builder.sets) == 0 {
+		return "", nil, errors.New("goe: invalid update. try sending one or more sets")
+	}
+
+	b := s.builder.copy()
+	if err := helperWhere(&b, s.fields, brs...); err != nil {
+		return "", nil, err
+	}
+	b.buildUpdate()
+
+	return render(b.sets[0].attribute.getDb().driver, &b.query)
+}
+
 type argSave struct {
 	sets        []set
 	argsWhere   []any
@@ -198,16 +237,18 @@ func getArgsSave[T any](addrMap map[uintptr]field, table *T, value T) argSave {
 	pksWhere, valuesWhere := make([]any, 0, valueOf.NumField()), make([]any, 0, valueOf.NumField())
 
 	var addr uintptr
-	for i := 0; i < valueOf.NumField(); i++ {
-		if !valueOf.Field(i).IsZero() {
-			addr = uintptr(tableOf.Field(i).Addr().UnsafePointer())
+	for _, field := range structFields(valueOf.Type()) {
+		if !fieldValue(valueOf, field.Index).IsZero() {
+			addr = uintptr(tableOf.FieldByIndex(field.Index).Addr().UnsafePointer())
 			if addrMap[addr] != nil {
 				if addrMap[addr].isPrimaryKey() {
-					pksWhere = append(pksWhere, tableOf.Field(i).Addr().Interface())
-					valuesWhere = append(valuesWhere, valueOf.Field(i).Interface())
+					pksWhere = append(pksWhere, tableOf.FieldByIndex(field.Index).Addr().Interface())
+					valuesWhere = append(valuesWhere, fieldValue(valueOf, field.Index).Interface())
 					continue
 				}
-				sets = append(sets, set{attribute: addrMap[addr], value: valueOf.Field(i).Interface()})
+				if addrMap[addr].getMode().canUpdate() {
+					sets = append(sets, set{attribute: addrMap[addr], value: fieldValue(valueOf, field.Index).Interface()})
+				}
 			}
 		}
 	}